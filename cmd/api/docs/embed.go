@@ -0,0 +1,9 @@
+package docs
+
+import "embed"
+
+// Files holds the OpenAPI spec generated by `make swag-init`, embedded so that
+// it is served from memory and works without network access to the host.
+//
+//go:embed swagger.json
+var Files embed.FS
@@ -2,22 +2,22 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"time"
 
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/jha-captech/blog/internal/app"
+	"github.com/jha-captech/blog/internal/app/deps"
 	"github.com/jha-captech/blog/internal/config"
+	"github.com/jha-captech/blog/internal/logging"
 	"github.com/jha-captech/blog/internal/middleare"
-	"github.com/jha-captech/blog/internal/routes"
-	"github.com/jha-captech/blog/internal/services"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 func main() {
@@ -36,106 +36,281 @@ func run(ctx context.Context) error {
 	}
 
 	// Create a structured logger, which will print logs in json format to the
-	// writer we specify.
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
+	// output target we configured.
+	logger, logCloser, err := logging.NewLogger(cfg.AppLog, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to create logger: %w", err)
+	}
+	defer logCloser.Close()
+
+	if cfg.TracingEnabled {
+		logger = slog.New(logging.NewTraceHandler(logger.Handler()))
+	}
+
+	// Create the security event logger, which can use a different target than
+	// the application logs so events can be retained and watched separately
+	securityLogger, securityCloser, err := logging.NewLogger(cfg.SecurityLog, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to create security logger: %w", err)
+	}
+	defer securityCloser.Close()
+
+	if cfg.TracingEnabled {
+		securityLogger = slog.New(logging.NewTraceHandler(securityLogger.Handler()))
+	}
+
+	// Create a container that builds the application's dependencies from the
+	// config as they are needed
+	container := deps.New(cfg, logger)
+	container.SecurityLogger = securityLogger
 
 	// Create a new DB connection using environment config
 	logger.DebugContext(ctx, "Connecting to database")
-	db, err := sql.Open("pgx", fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		cfg.DBHost,
-		cfg.DBUserName,
-		cfg.DBUserPassword,
-		cfg.DBName,
-		cfg.DBPort,
-	))
+	db, err := container.DB(ctx)
 	if err != nil {
-		return fmt.Errorf("[in database.Connect] failed to open database: %w", err)
+		return fmt.Errorf("[in main.run] %w", err)
 	}
 
-	// Ping the database to verify connection
-	logger.DebugContext(ctx, "Pinging database")
-	if err = db.PingContext(ctx); err != nil {
-		return fmt.Errorf("[in database.Connect] failed to ping database: %w", err)
+	logger.InfoContext(ctx, "Connected successfully to the database")
+
+	// Components are started in the order they are appended and stopped in
+	// reverse, so the database is closed only once nothing is using it.
+	lifecycle := app.New(logger)
+	lifecycle.Append(app.Hook{
+		Name: "database",
+		Stop: func(context.Context) error {
+			return db.Close()
+		},
+	})
+
+	// Fault injection for resilience testing, when configured
+	if container.Chaos() != nil {
+		logger.WarnContext(ctx, "Chaos fault injection is enabled")
+	}
+
+	// Sample dependency health in the background, keeping recent results to
+	// diagnose intermittent failures
+	healthMonitor, err := container.HealthMonitor(ctx)
+	if err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
 	}
 
-	defer func() {
-		logger.DebugContext(ctx, "Closing database connection")
-		if err = db.Close(); err != nil {
-			logger.ErrorContext(ctx, "Failed to close database connection", "err", err)
+	monitorCtx, stopMonitor := context.WithCancel(ctx)
+	defer stopMonitor()
+	monitorDone := make(chan struct{})
+	lifecycle.Append(app.Hook{
+		Name: "health monitor",
+		Start: func(context.Context) error {
+			go func() {
+				defer close(monitorDone)
+				healthMonitor.Run(monitorCtx, cfg.HealthCheckInterval)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			stopMonitor()
+			select {
+			case <-monitorDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	// Measure scheduler lag in the background for overload protection
+	shedder := container.Shedder()
+	shedderCtx, stopShedder := context.WithCancel(ctx)
+	defer stopShedder()
+	lifecycle.Append(app.Hook{
+		Name: "load shedder",
+		Start: func(context.Context) error {
+			go shedder.Run(shedderCtx, cfg.LoadShedSampleInterval)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			stopShedder()
+			return nil
+		},
+	})
+
+	// Embed new and updated posts in the background for semantic search, when
+	// an embedding provider is configured
+	embeddingWorker, err := container.EmbeddingWorker(ctx)
+	if err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
+	}
+	if embeddingWorker != nil {
+		workerCtx, stopWorker := context.WithCancel(ctx)
+		defer stopWorker()
+		workerDone := make(chan struct{})
+		lifecycle.Append(app.Hook{
+			Name: "embedding worker",
+			Start: func(context.Context) error {
+				go func() {
+					defer close(workerDone)
+					embeddingWorker.Run(workerCtx, cfg.EmbeddingInterval)
+				}()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				stopWorker()
+				select {
+				case <-workerDone:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+	}
+
+	// Create the API's routes, wrapped with middleware
+	handler, err := container.Handler(ctx)
+	if err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
+	}
+	budgets := container.LatencyBudgets()
+
+	// Create the access log output, which can use a different target than the
+	// application logs. The Combined Log Format is written as plain lines
+	// rather than through slog, in which case slow request warnings go to the
+	// application logger.
+	accessLogger := logger
+	accessOptions := middleare.LoggerOptions{
+		Budgets: budgets,
+		Format:  cfg.AccessLogFormat,
+	}
+	if cfg.AccessLogFormat == middleare.AccessLogCombined {
+		var accessCloser io.Closer
+		accessOptions.Output, accessCloser, err = logging.NewWriter(cfg.AccessLog)
+		if err != nil {
+			return fmt.Errorf("[in main.run] failed to create access log writer: %w", err)
+		}
+		defer accessCloser.Close()
+	} else {
+		var accessCloser io.Closer
+		accessLogger, accessCloser, err = logging.NewLogger(cfg.AccessLog, cfg.LogLevel)
+		if err != nil {
+			return fmt.Errorf("[in main.run] failed to create access logger: %w", err)
 		}
-	}()
+		defer accessCloser.Close()
 
-	logger.InfoContext(ctx, "Connected successfully to the database")
+		if cfg.TracingEnabled {
+			accessLogger = slog.New(logging.NewTraceHandler(accessLogger.Handler()))
+		}
+	}
+
+	// Resolve the client address and request id before logging, so access
+	// logs show the client rather than the proxy in front of the server, and
+	// can be joined with the application logs of the same request
+	resolver, err := container.RealIP()
+	if err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
+	}
 
-	// Create a new users service
-	usersService := services.NewUsersService(logger, db)
+	wrappedMux := middleare.RequestID()(middleare.RealIP(resolver)(middleare.Logger(accessLogger, accessOptions)(handler)))
 
-	// Create a serve mux to act as our route multiplexer
-	mux := http.NewServeMux()
+	// Load the certificate shared by the HTTPS and HTTP/3 listeners
+	var tlsConfig *tls.Config
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("[in main.run] failed to load TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
 
-	// Add our routes to the mux
-	routes.AddRoutes(mux, logger, usersService, fmt.Sprintf("http://%s:%s", cfg.Host, cfg.Port))
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
 
-	// Wrap the mux with middleware
-	wrappedMux := middleare.Logger(logger)(mux)
+	// Create an HTTP/3 server on the same port over UDP, and advertise it on
+	// responses from the TCP server
+	var http3Server *http3.Server
+	if cfg.HTTP3Enabled {
+		http3Server = &http3.Server{
+			Addr:      addr,
+			Handler:   wrappedMux,
+			TLSConfig: http3.ConfigureTLSConfig(tlsConfig.Clone()),
+		}
+		wrappedMux = middleare.AltSvc(http3Server)(wrappedMux)
+	}
 
 	// Create a new http server with our mux as the handler
 	httpServer := &http.Server{
-		Addr:    net.JoinHostPort(cfg.Host, cfg.Port),
-		Handler: wrappedMux,
+		Addr:      addr,
+		Handler:   wrappedMux,
+		TLSConfig: tlsConfig,
 	}
 
-	errChan := make(chan error)
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
 
-	// Server run context
-	ctx, done := context.WithCancel(ctx)
-	defer done()
+	lifecycle.Append(app.Hook{
+		Name: "http server",
+		// Listening before returning surfaces errors such as the port being in
+		// use at startup.
+		Start: func(context.Context) error {
+			listener, err := net.Listen("tcp", httpServer.Addr)
+			if err != nil {
+				return err
+			}
 
-	// Handle graceful shutdown with go routine on SIGINT
-	go func() {
-		// create a channel to listen for SIGINT and then block until it is received
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, os.Interrupt)
-		<-sig
+			logger.InfoContext(ctx, "listening", slog.String("address", httpServer.Addr), slog.String("scheme", scheme))
+			logger.InfoContext(ctx, "Swagger running", slog.String("url", scheme+"://"+httpServer.Addr+"/swagger/index.html"))
 
-		logger.DebugContext(ctx, "Received SIGINT, shutting down server")
+			go func() {
+				// The certificate is already set in TLSConfig.
+				serve := httpServer.Serve
+				if tlsConfig != nil {
+					serve = func(l net.Listener) error { return httpServer.ServeTLS(l, "", "") }
+				}
 
-		// Create a context with a timeout to allow the server to shut down gracefully
-		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
+				if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					lifecycle.Fail(fmt.Errorf("[in main.run] failed to serve: %w", err))
+				}
+			}()
+			return nil
+		},
+		// Shutdown waits for in-flight requests to finish.
+		Stop: func(ctx context.Context) error {
+			return httpServer.Shutdown(ctx)
+		},
+	})
 
-		// Shutdown the server. If an error occurs, send it to the error channel
-		if err = httpServer.Shutdown(ctx); err != nil {
-			errChan <- fmt.Errorf("[in main.run] failed to shutdown http server: %w", err)
-			return
-		}
+	if http3Server != nil {
+		lifecycle.Append(app.Hook{
+			Name: "http3 server",
+			Start: func(context.Context) error {
+				conn, err := net.ListenPacket("udp", http3Server.Addr)
+				if err != nil {
+					return err
+				}
 
-		// Close the idle connections channel, unblocking `run()`
-		done()
-	}()
-
-	// Start the http server
-	logger.InfoContext(ctx, "listening", slog.String("address", httpServer.Addr))
-	if err = httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		switch {
-		// once httpServer.Shutdown is called, it will always return an
-		// http.ErrServerClosed error and we don't care about that error so we will
-		// break.
-		case errors.Is(err, http.ErrServerClosed):
-			break
-		default:
-			return fmt.Errorf("[in main.run] failed to listen and serve: %w", err)
-		}
+				logger.InfoContext(ctx, "listening", slog.String("address", http3Server.Addr), slog.String("scheme", "h3"))
+
+				go func() {
+					if err := http3Server.Serve(conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						lifecycle.Fail(fmt.Errorf("[in main.run] failed to serve http3: %w", err))
+					}
+				}()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				return http3Server.Shutdown(ctx)
+			},
+		})
 	}
 
-	// block until the server is shut down or an error occurs
-	select {
-	case err = <-errChan:
-		return err
-	case <-ctx.Done():
-		return nil
+	// Start every component and block until the server is interrupted or a
+	// component fails, then stop them all
+	if err = lifecycle.Run(ctx); err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
 	}
+
+	return nil
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "dev: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run brings up the database container, then builds and runs the API,
+// rebuilding and restarting it whenever a Go file changes.
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("dev", flag.ContinueOnError)
+	down := flags.Bool("down", false, "stop the containers when exiting")
+	interval := flags.Duration("interval", time.Second, "how often to check for changed files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// The setup script mounted into the container creates and seeds the schema
+	// the first time the volume is initialized.
+	logf("Starting database...")
+	if err := command(ctx, "docker", "compose", "up", "-d", "--wait").Run(); err != nil {
+		return fmt.Errorf("start database: %w", err)
+	}
+
+	if *down {
+		defer func() {
+			logf("Stopping database...")
+			if err := command(context.Background(), "docker", "compose", "down").Run(); err != nil {
+				logf("failed to stop database: %s", err)
+			}
+		}()
+	}
+
+	binary := filepath.Join(os.TempDir(), "blog-api-dev")
+	lastChange, err := latestChange(".")
+	if err != nil {
+		return fmt.Errorf("scan source files: %w", err)
+	}
+
+	for {
+		api, err := start(ctx, binary)
+		if err != nil {
+			logf("%s", err)
+		}
+
+		// Wait for a source change or for the command to be stopped.
+		ticker := time.NewTicker(*interval)
+		for changed := false; !changed; {
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				stopAPI(api)
+				return nil
+			case <-ticker.C:
+				latest, err := latestChange(".")
+				if err != nil {
+					return fmt.Errorf("scan source files: %w", err)
+				}
+				if latest.After(lastChange) {
+					lastChange = latest
+					changed = true
+				}
+			}
+		}
+		ticker.Stop()
+
+		logf("Change detected, restarting API...")
+		stopAPI(api)
+	}
+}
+
+// start builds the API into binary and starts it, returning the running
+// process.
+func start(ctx context.Context, binary string) (*exec.Cmd, error) {
+	logf("Building API...")
+	if err := command(ctx, "go", "build", "-o", binary, "./cmd/api").Run(); err != nil {
+		return nil, fmt.Errorf("build failed, waiting for changes: %w", err)
+	}
+
+	api := command(context.Background(), binary)
+	if err := api.Start(); err != nil {
+		return nil, fmt.Errorf("start api: %w", err)
+	}
+
+	logf("API started")
+	return api, nil
+}
+
+// stopAPI interrupts the running API, letting it shut down gracefully, and
+// waits for it to exit.
+func stopAPI(api *exec.Cmd) {
+	if api == nil || api.Process == nil {
+		return
+	}
+
+	_ = api.Process.Signal(os.Interrupt)
+
+	done := make(chan struct{})
+	go func() {
+		_ = api.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(15 * time.Second):
+		_ = api.Process.Kill()
+		<-done
+	}
+}
+
+// latestChange returns the most recent modification time of any Go source file
+// or .env file under root.
+func latestChange(root string) (time.Time, error) {
+	var latest time.Time
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, ".go") && d.Name() != ".env" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return latest, err
+}
+
+// command creates a command that writes to the standard streams.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// logf prints a highlighted progress message.
+func logf(format string, args ...any) {
+	fmt.Printf("\033[0;36m"+format+"\033[0m\n", args...)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+
+	"github.com/jha-captech/blog/internal/app/deps"
+	"github.com/jha-captech/blog/internal/config"
+	"github.com/jha-captech/blog/internal/logging"
+	"github.com/jha-captech/blog/internal/middleare"
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+func main() {
+	if err := run(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "lambda encountered an error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run adapts the API to Lambda events. Only config and logging are set up
+// during the cold start; the database connection and routes are created by
+// the first request, keeping the init phase short.
+func run() error {
+	cfg, err := config.New()
+	if err != nil {
+		return fmt.Errorf("[in main.run] failed to load config: %w", err)
+	}
+
+	// Lambda sends stdout to CloudWatch, so file and syslog outputs are not
+	// used here.
+	logger := slog.New(logging.NewRequestIDHandler(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel}),
+	))
+	if cfg.TracingEnabled {
+		logger = slog.New(logging.NewTraceHandler(logger.Handler()))
+	}
+
+	container := deps.New(cfg, logger)
+
+	resolver, err := container.RealIP()
+	if err != nil {
+		return fmt.Errorf("[in main.run] %w", err)
+	}
+
+	handler := middleare.RequestID()(middleare.RealIP(resolver)(middleare.Logger(logger, middleare.LoggerOptions{
+		Budgets: container.LatencyBudgets(),
+		Format:  middleare.AccessLogJSON,
+	})(&lazyHandler{logger: logger, build: container.Handler})))
+
+	// The connection pool is reused by warm invocations and never closed, as
+	// Lambda gives no reliable signal before freezing or terminating the
+	// execution environment.
+	switch cfg.LambdaEventSource {
+	case config.LambdaEventAPIGatewayV2:
+		lambda.Start(httpadapter.NewV2(handler).ProxyWithContext)
+	case config.LambdaEventALB:
+		lambda.Start(httpadapter.NewALB(handler).ProxyWithContext)
+	default:
+		lambda.Start(httpadapter.New(handler).ProxyWithContext)
+	}
+
+	return nil
+}
+
+// lazyHandler builds its handler on the first request. If building fails the
+// request is rejected and the next request tries again.
+type lazyHandler struct {
+	logger *slog.Logger
+	build  func(ctx context.Context) (http.Handler, error)
+
+	mu      sync.Mutex
+	handler http.Handler
+}
+
+func (h *lazyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, err := h.get(r.Context())
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to initialize handler", slog.String("error", err.Error()))
+		problem.Error(w, r, http.StatusServiceUnavailable, "")
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// get returns the handler, building it if needed.
+func (h *lazyHandler) get(ctx context.Context) (http.Handler, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.handler != nil {
+		return h.handler, nil
+	}
+
+	handler, err := h.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	h.handler = handler
+	return h.handler, nil
+}
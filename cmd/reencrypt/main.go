@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jha-captech/blog/internal/app/deps"
+	"github.com/jha-captech/blog/internal/config"
+	"github.com/jha-captech/blog/internal/logging"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "reencrypt: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run encrypts the sensitive columns of rows stored before encryption was
+// enabled, and re-encrypts those using a key other than the current one. Run it
+// after deploying a new current key and before removing the old one from
+// ENCRYPTION_KEYS. It is safe to run repeatedly, and while the API is serving.
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	batchSize := flags.Int("batch", 500, "number of rows read at a time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("-batch must be positive")
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger, logCloser, err := logging.NewLogger(cfg.AppLog, cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("create logger: %w", err)
+	}
+	defer logCloser.Close()
+
+	container := deps.New(cfg, logger)
+
+	db, err := container.DB(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	usersService, err := container.UsersService(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated, err := usersService.ReencryptEmails(ctx, *batchSize)
+	logger.InfoContext(ctx, "Re-encrypted user emails", "updated", updated)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
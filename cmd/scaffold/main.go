@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// sqlTypes maps the field types accepted on the command line to their SQL column
+// types.
+var sqlTypes = map[string]string{
+	"string":    "TEXT",
+	"int":       "INTEGER",
+	"int64":     "BIGINT",
+	"uint":      "BIGINT",
+	"float64":   "REAL",
+	"bool":      "BOOLEAN",
+	"time.Time": "TIMESTAMP",
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "scaffold: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("scaffold", flag.ContinueOnError)
+	name := fs.String("name", "", "singular resource name in snake_case, e.g. blog_post")
+	fields := fs.String("fields", "", "comma separated name:type pairs, e.g. title:string,author_id:uint")
+	root := fs.String("root", ".", "repository root to write files into")
+	force := fs.Bool("force", false, "overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	r, err := newResource(*name, *fields)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		template string
+		path     string
+	}{
+		{"model.go.tmpl", filepath.Join("internal", "models", r.Snake+".go")},
+		{"service.go.tmpl", filepath.Join("internal", "services", r.Snake+".go")},
+		{"read_handler.go.tmpl", filepath.Join("internal", "handlers", "read_"+r.Snake+".go")},
+		{"create_handler.go.tmpl", filepath.Join("internal", "handlers", "create_"+r.Snake+".go")},
+	}
+
+	tmpl, err := template.New("scaffold").
+		Funcs(template.FuncMap{
+			"inc": func(i int) int { return i + 1 },
+			"add": func(a, b int) int { return a + b },
+		}).
+		ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("parse templates: %w", err)
+	}
+
+	for _, f := range files {
+		path := filepath.Join(*root, f.path)
+
+		if _, err := os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, f.template, r); err != nil {
+			return fmt.Errorf("render %s: %w", f.template, err)
+		}
+
+		out := buf.Bytes()
+		if strings.HasSuffix(path, ".go") {
+			if out, err = format.Source(out); err != nil {
+				return fmt.Errorf("format %s: %w", path, err)
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, out, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+
+		fmt.Println("created", path)
+	}
+
+	// Route registration and the table definition are printed rather than
+	// written, as they need to be merged into existing files by hand.
+	var routes, migration bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&routes, "routes.tmpl", r); err != nil {
+		return fmt.Errorf("render routes.tmpl: %w", err)
+	}
+	if err := tmpl.ExecuteTemplate(&migration, "migration.sql.tmpl", r); err != nil {
+		return fmt.Errorf("render migration.sql.tmpl: %w", err)
+	}
+
+	fmt.Printf("\nAdd the following to routes.AddRoutes and pass a %s from main.run:\n\n%s", r.Service, routes.String())
+	fmt.Printf("\nAdd the following to database_postgres_setup.sql (and the MySQL and SQLite setup files):\n\n%s", migration.String())
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resource holds the names used when rendering the templates for a single
+// resource.
+type resource struct {
+	// Snake is the singular snake_case name, e.g. blog_post.
+	Snake string
+	// Human is the singular name used in comments and messages, e.g. blog post.
+	Human string
+	// Type is the exported Go type name, e.g. BlogPost.
+	Type string
+	// Var is the unexported Go variable name, e.g. blogPost.
+	Var string
+	// Table is the plural table and route name, e.g. blog_posts.
+	Table string
+	// Path is the plural route segment, e.g. blog-posts.
+	Path string
+	// Service is the service type name, e.g. BlogPostsService.
+	Service string
+	// Plural is the exported plural name, e.g. BlogPosts.
+	Plural string
+
+	Fields []field
+}
+
+// field is a single column on a resource, excluding the id.
+type field struct {
+	// Column is the snake_case column and json name.
+	Column string
+	// Name is the exported Go field name.
+	Name string
+	// GoType is the Go type of the field.
+	GoType string
+	// SQLType is the column type used in the migration.
+	SQLType string
+}
+
+// newResource builds a resource from a snake_case name and a comma separated
+// list of name:type field definitions.
+func newResource(name string, fields string) (resource, error) {
+	plural := pluralize(name)
+
+	r := resource{
+		Snake:   name,
+		Human:   strings.ReplaceAll(name, "_", " "),
+		Type:    pascal(name),
+		Var:     camel(name),
+		Table:   plural,
+		Path:    strings.ReplaceAll(plural, "_", "-"),
+		Plural:  pascal(plural),
+		Service: pascal(plural) + "Service",
+	}
+
+	for _, def := range strings.Split(fields, ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+
+		column, goType, ok := strings.Cut(def, ":")
+		if !ok {
+			return resource{}, fmt.Errorf("field %q must be in the form name:type", def)
+		}
+
+		sqlType, ok := sqlTypes[goType]
+		if !ok {
+			return resource{}, fmt.Errorf("field %q has unsupported type %q", column, goType)
+		}
+
+		if column == "id" {
+			return resource{}, fmt.Errorf("field %q is added automatically", column)
+		}
+
+		r.Fields = append(r.Fields, field{
+			Column:  column,
+			Name:    pascal(column),
+			GoType:  goType,
+			SQLType: sqlType,
+		})
+	}
+
+	if len(r.Fields) == 0 {
+		return resource{}, fmt.Errorf("at least one field is required")
+	}
+
+	return r, nil
+}
+
+// HasTime reports whether any field is a time.Time, requiring the time import.
+func (r resource) HasTime() bool {
+	for _, f := range r.Fields {
+		if f.GoType == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+// Columns returns the comma separated column names, excluding the id.
+func (r resource) Columns() string {
+	columns := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		columns[i] = f.Column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Placeholders returns $N placeholders for every field, starting at start.
+func (r resource) Placeholders(start int) string {
+	placeholders := make([]string, len(r.Fields))
+	for i := range r.Fields {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Sets returns the column = $N assignments for an UPDATE, starting at start.
+func (r resource) Sets(start int) string {
+	sets := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		sets[i] = fmt.Sprintf("%s = $%d", f.Column, start+i)
+	}
+	return strings.Join(sets, ",\n\t\t       ")
+}
+
+// Placeholder returns the $N placeholder for n.
+func (r resource) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// pascal converts a snake_case name to PascalCase, upper casing the id
+// initialism.
+func pascal(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		switch part {
+		case "id", "url", "api":
+			parts[i] = strings.ToUpper(part)
+		default:
+			if part != "" {
+				parts[i] = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// camel converts a snake_case name to camelCase.
+func camel(name string) string {
+	p := pascal(name)
+	return strings.ToLower(p[:1]) + p[1:]
+}
+
+// pluralize returns a naive English plural of a snake_case name.
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ey"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	default:
+		return name + "s"
+	}
+}
@@ -0,0 +1,235 @@
+-- Schema used when running against MySQL. Keep in sync with
+-- database_postgres_setup.sql.
+DROP TABLE IF EXISTS canaries;
+DROP TABLE IF EXISTS user_changes;
+DROP TABLE IF EXISTS csp_reports;
+DROP TABLE IF EXISTS announcements;
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS post_embeddings;
+DROP TABLE IF EXISTS summaries;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS blogs;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS users;
+
+-- Create user table. Emails are stored encrypted, and found by email_index,
+-- their blind index, which is unique so no two users share an email. Both are
+-- filled in by the application, so seeded rows hold plaintext emails until the
+-- reencrypt command is run.
+CREATE TABLE users (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    email_index VARCHAR(64),
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX users_email_index (email_index)
+);
+
+-- Create user change table. A row is added whenever a user is created, updated
+-- or deleted, in the same transaction, so syncing clients can follow changes
+-- in seq order and learn about deleted users. Rows are kept after their user is
+-- deleted, so user_id does not reference users.
+CREATE TABLE user_changes (
+    seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    INDEX user_changes_user_id (user_id, seq)
+);
+
+-- Create canary table. Canaries are decoy users whose use raises a security
+-- alert, and are deleted along with their user.
+CREATE TABLE canaries (
+    user_id BIGINT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Create post table. Posts are deleted along with their author. The summary
+-- is generated on request and cleared when the post is edited.
+CREATE TABLE posts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    author_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    summary TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE,
+    FULLTEXT INDEX posts_text (title, body)
+);
+
+-- Create post embedding table. Embeddings are supplied by clients and stored
+-- in pgvector's text format, e.g. "[0.1,0.2]".
+CREATE TABLE post_embeddings (
+    post_id BIGINT PRIMARY KEY,
+    embedding MEDIUMTEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+
+-- Create summary cache table. Generated summaries are keyed by the SHA-256
+-- hash of the content they summarize, so unchanged content is never sent to
+-- the language model twice.
+CREATE TABLE summaries (
+    content_hash CHAR(64) PRIMARY KEY,
+    summary TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create comment table. Comments are deleted along with their post or
+-- author.
+CREATE TABLE comments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX comments_post_id (post_id, created_at),
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Create analytics event table
+CREATE TABLE events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    path TEXT NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    value DOUBLE,
+    client_ip VARCHAR(45),
+    user_agent TEXT,
+    occurred_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP NOT NULL,
+    INDEX events_received_at (received_at)
+);
+
+-- Create settings table, holding a single row of blog-level settings
+CREATE TABLE settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    data JSON NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create announcement table
+CREATE TABLE announcements (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    message TEXT NOT NULL,
+    audience VARCHAR(16) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX announcements_window (starts_at, ends_at)
+);
+
+-- Create Content Security Policy violation report table
+CREATE TABLE csp_reports (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    document_uri TEXT NOT NULL,
+    blocked_uri TEXT NOT NULL,
+    effective_directive VARCHAR(64) NOT NULL,
+    disposition VARCHAR(16) NOT NULL,
+    source_file TEXT,
+    line_number INT,
+    received_at TIMESTAMP NOT NULL,
+    INDEX csp_reports_received_at (received_at)
+);
+
+-- Insert data into the user table. The passwords are password1 to password10,
+-- in order, hashed with bcrypt. The last two users are canaries with random
+-- passwords, rotate them to get credentials to plant.
+INSERT INTO users (name, email, password_hash) VALUES
+    ('John Doe', 'john@example.com', '$2a$10$F3rJq0INONM26RBoFb6JK.s/SCdJhzCUvYUUnHBxiMnhvKZ1x4XkW'),
+    ('Jane Smith', 'jane@example.com', '$2a$10$W5U9HOkvi5.NGv7fTlWDguGAtAoUU46m4nualenNYQxqyqQDdzZlq'),
+    ('Alice Johnson', 'alice@example.com', '$2a$10$75/kHXM7okvcVob7xx/Gpe/2UAlOyqwWh7bum5G7BFraX3WL/B5r.'),
+    ('Bob Brown', 'bob@example.com', '$2a$10$drja0fP69hoXKBAtyt38tOg.pBoYR9dh2fveV9vZhkLvLi2O/XsHO'),
+    ('Emma Davis', 'emma@example.com', '$2a$10$K9yh8YQpt.Sj1aJdbqLzTOR7eVQGQQa4EnboVE68WD.xx88BIr8OG'),
+    ('Michael Wilson', 'michael@example.com', '$2a$10$qFWb5YTnohnjgLBYfykVR.jDwXA5DJoHnPA2GQp8aD4hTMnLG8Iz2'),
+    ('Sarah Lee', 'sarah@example.com', '$2a$10$FpUyUaWRW23QyndZEOGyMO7wW5c38ni5mhIRfMRzh4/7EPR.S20A.'),
+    ('David Garcia', 'david@example.com', '$2a$10$.m/nTLFB8lm4HVrcJOoxgu74qurGM/QgFNWPJjKpy3mjfDu8HpYYS'),
+    ('Olivia Martinez', 'olivia@example.com', '$2a$10$QpKM3zIBJxBZMntxSzay8uhJ1k9ojc5vI1ynHuotfm8UCrUgX9cRe'),
+    ('William Rodriguez', 'william@example.com', '$2a$10$JWfhJ..azBqfgJ45PDSeBOfqNORZX6iZH6N35W9m5BPzR7YQOGDEm'),
+    ('Daniel Harris', 'daniel.harris@example.com', '$2a$10$MG7KYtuPkXRQHnAh7WxnyOyZ3u8XJ0mKXUioJlO.kpgEqbEgANHny'),
+    ('Laura Bennett', 'laura.bennett@example.com', '$2a$10$aI.Wu.m75CsxgAunA0wgK.0KiM/y1V8qGiykQaTqzulOblURJ/XMe');
+
+-- Record the seeded users as changes, so they are returned by the first sync
+INSERT INTO user_changes (user_id) SELECT id FROM users ORDER BY id;
+
+-- Mark the canary users
+INSERT INTO canaries (user_id) SELECT id FROM users WHERE email IN ('daniel.harris@example.com', 'laura.bennett@example.com');
+
+-- Insert data into the post table
+INSERT INTO posts (author_id, title, body, created_at, updated_at) VALUES
+    (1, 'First Blog Post', 'Welcome to the blog! This is where I will be sharing whatever I am working on.', '2024-05-14 09:00:00', '2024-05-14 09:00:00'),
+    (2, 'Travel Adventures', 'Two weeks, four cities and far too many train stations. Here is how it went.', '2024-05-13 14:30:00', '2024-05-13 14:30:00'),
+    (3, 'Cooking Tips', 'A few small habits that made my weeknight cooking faster and tastier.', '2024-05-12 11:45:00', '2024-05-12 11:45:00'),
+    (4, 'Tech Reviews', 'My honest thoughts on the gadgets I used most this year.', '2024-05-11 16:20:00', '2024-05-11 16:20:00'),
+    (5, 'Fitness Journey', 'Six months in, here is what changed and what I would do differently.', '2024-05-10 08:15:00', '2024-05-10 08:15:00'),
+    (6, 'Book Recommendations', 'The books I could not put down this spring, in no particular order.', '2024-05-09 10:45:00', '2024-05-09 10:45:00'),
+    (7, 'Photography Tips', 'Better photos start with light. Some tips for shooting at any time of day.', '2024-05-08 13:20:00', '2024-05-08 13:20:00'),
+    (8, 'Financial Advice', 'Simple steps to build a budget you will actually stick to.', '2024-05-07 17:30:00', '2024-05-07 17:30:00'),
+    (9, 'DIY Projects', 'A weekend shelving project that anyone with a drill can finish.', '2024-05-06 09:45:00', '2024-05-06 09:45:00'),
+    (10, 'Movie Reviews', 'The films worth your time this month, and a few worth skipping.', '2024-05-05 14:00:00', '2024-05-05 14:00:00'),
+    (1, 'Second Blog Post', 'Thanks for all the feedback on the first post! Here is what is coming next.', '2024-05-04 11:10:00', '2024-05-04 11:10:00'),
+    (2, 'Healthy Recipes', 'Quick, healthy dinners that do not taste like a compromise.', '2024-05-03 15:25:00', '2024-05-03 15:25:00'),
+    (3, 'Productivity Hacks', 'The routines that help me get more done with less stress.', '2024-05-02 10:50:00', '2024-05-02 10:50:00'),
+    (4, 'Gaming News', 'A roundup of the biggest announcements from this week in gaming.', '2024-05-01 12:15:00', '2024-05-01 12:15:00'),
+    (5, 'Home Decor Ideas', 'Small changes that make a room feel brand new.', '2024-04-30 09:30:00', '2024-04-30 09:30:00');
+
+-- Insert data into the comment table
+INSERT INTO comments (post_id, author_id, message, created_at, updated_at) VALUES
+    (8, 1, 'Saving money has never been easier with these tips!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (11, 2, 'I agree with your points.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (4, 2, 'Exciting developments in the tech world.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (5, 4, 'Sweat is just fat crying.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (3, 5, 'Perfect for a cozy night in.', '2024-05-15 13:00:00', '2024-05-15 13:00:00'),
+    (2, 1, 'What a beautiful destination!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (5, 1, 'Feeling the burn!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 1, 'Great post!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (3, 1, 'This recipe looks delicious!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (10, 5, 'I laughed, I cried, I loved it.', '2024-05-15 13:00:00', '2024-05-15 13:00:00'),
+    (9, 2, 'Getting crafty with this idea.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (2, 2, 'I wish I could visit there someday.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (14, 1, 'Exciting news in the gaming world!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (13, 1, 'These productivity tips are game-changers!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 6, 'Interesting perspective.', '2024-05-15 13:15:00', '2024-05-15 13:15:00'),
+    (7, 2, 'Improving my photography skills one tip at a time.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (6, 2, 'Love the recommendation!', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (15, 2, 'Can''t wait to redecorate my space.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (10, 4, 'A must-watch for any movie buff.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (3, 2, 'Can''t wait to try this at home.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (10, 1, 'This movie was amazing!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (11, 3, 'This made me think.', '2024-05-15 12:30:00', '2024-05-15 12:30:00'),
+    (12, 7, 'Any suggestions for substitutions?', '2024-05-15 13:30:00', '2024-05-15 13:30:00'),
+    (15, 9, 'Home decor is my passion.', '2024-05-15 14:00:00', '2024-05-15 14:00:00'),
+    (7, 3, 'Can''t wait to try this technique.', '2024-05-15 12:30:00', '2024-05-15 12:30:00'),
+    (7, 10, 'Ready to capture the world.', '2024-05-15 14:15:00', '2024-05-15 14:15:00'),
+    (14, 4, 'The graphics in this trailer look amazing.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (1, 4, 'Thanks for sharing.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (8, 3, 'Planning for the future with smart investments.', '2024-05-15 12:30:00', '2024-05-15 12:30:00'),
+    (12, 1, 'This recipe looks delicious and healthy!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (7, 7, 'Any tips for shooting in low light?', '2024-05-15 13:30:00', '2024-05-15 13:30:00'),
+    (15, 5, 'Creating a cozy atmosphere with these tips.', '2024-05-15 13:00:00', '2024-05-15 13:00:00'),
+    (4, 1, 'This new technology is groundbreaking!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (7, 1, 'Captured a beautiful moment thanks to this tip!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (6, 4, 'Excited to dive into this story.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (15, 4, 'Adding these decor ideas to my Pinterest board.', '2024-05-15 12:45:00', '2024-05-15 12:45:00'),
+    (5, 2, 'Pushing past my limits.', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (6, 1, 'Adding this to my reading list!', '2024-05-15 12:00:00', '2024-05-15 12:00:00'),
+    (1, 3, 'Insightful!', '2024-05-15 12:30:00', '2024-05-15 12:30:00'),
+    (14, 2, 'Can''t wait for this game to be released!', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (14, 6, 'Hyped for the upcoming esports tournament.', '2024-05-15 13:15:00', '2024-05-15 13:15:00'),
+    (13, 5, 'Feeling more focused and motivated already.', '2024-05-15 13:00:00', '2024-05-15 13:00:00'),
+    (15, 3, 'This room makeover is goals!', '2024-05-15 12:30:00', '2024-05-15 12:30:00'),
+    (8, 10, 'Ready to build wealth and achieve my goals.', '2024-05-15 14:15:00', '2024-05-15 14:15:00'),
+    (5, 7, 'Taking my fitness journey one step at a time.', '2024-05-15 13:30:00', '2024-05-15 13:30:00'),
+    (11, 6, 'Can you elaborate more?', '2024-05-15 13:15:00', '2024-05-15 13:15:00'),
+    (9, 7, 'Any tips for beginners?', '2024-05-15 13:30:00', '2024-05-15 13:30:00'),
+    (12, 2, 'Can''t wait to try this nutritious dish!', '2024-05-15 12:15:00', '2024-05-15 12:15:00'),
+    (10, 10, '10/10 would watch again.', '2024-05-15 14:15:00', '2024-05-15 14:15:00'),
+    (14, 9, 'Ready to level up!', '2024-05-15 14:00:00', '2024-05-15 14:00:00'),
+    (5, 6, 'No pain, no gain!', '2024-05-15 13:15:00', '2024-05-15 13:15:00');
@@ -0,0 +1,725 @@
+// Package deps assembles the application's dependency graph. Each provider
+// builds its dependency on first use and returns the same instance afterwards,
+// so commands and tests only construct the parts of the graph they need.
+package deps
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/chaos"
+	"github.com/jha-captech/blog/internal/clock"
+	"github.com/jha-captech/blog/internal/config"
+	"github.com/jha-captech/blog/internal/crypto"
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/embeddings"
+	"github.com/jha-captech/blog/internal/health"
+	"github.com/jha-captech/blog/internal/httpclient"
+	"github.com/jha-captech/blog/internal/lint"
+	"github.com/jha-captech/blog/internal/llm"
+	"github.com/jha-captech/blog/internal/metrics"
+	"github.com/jha-captech/blog/internal/middleare"
+	"github.com/jha-captech/blog/internal/overload"
+	"github.com/jha-captech/blog/internal/password"
+	"github.com/jha-captech/blog/internal/ratelimit"
+	"github.com/jha-captech/blog/internal/realip"
+	"github.com/jha-captech/blog/internal/routes"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/slo"
+)
+
+// Container holds the application's configuration and the dependencies built
+// from it. Providers are not safe for concurrent use and are expected to be
+// called while the application is being wired up.
+type Container struct {
+	Config config.Config
+	Logger *slog.Logger
+	Clock  clock.Clock
+	// SecurityLogger receives security events. The application logger is used
+	// when it is nil.
+	SecurityLogger *slog.Logger
+
+	chaos          *chaos.Injector
+	db             *database.DB
+	usersService   *services.UsersService
+	eventsService  *services.EventsService
+	settings       *services.SettingsService
+	announcements  *services.AnnouncementsService
+	posts          *services.PostsService
+	comments       *services.CommentsService
+	cspReports     *services.CSPReportsService
+	canaries       *services.CanariesService
+	dependencies   *services.DependenciesService
+	sloTracker     *slo.Tracker
+	healthMonitor  *health.Monitor
+	httpClient     *httpclient.Client
+	realIP         *realip.Resolver
+	shedder        *overload.Shedder
+	tokens         *auth.Tokens
+	keyring        *crypto.Keyring
+	metrics        *metrics.Registry
+	securityEvents *events.Emitter
+	summaries      *services.SummariesService
+	linter         *lint.Linter
+	embedder       embeddings.Provider
+	embedWorker    *embeddings.Worker
+	llm            llm.Provider
+	rateLimits     *ratelimit.MemoryStore
+}
+
+// New creates a new Container using the system clock and returns a pointer to
+// it.
+func New(cfg config.Config, logger *slog.Logger) *Container {
+	return &Container{
+		Config: cfg,
+		Logger: logger,
+		Clock:  clock.Real{},
+	}
+}
+
+// Chaos returns the fault injector, or nil when chaos testing is disabled.
+func (c *Container) Chaos() *chaos.Injector {
+	if c.chaos == nil && c.Config.ChaosEnabled {
+		c.chaos = &chaos.Injector{
+			Latency:     c.Config.ChaosLatency,
+			LatencyRate: c.Config.ChaosLatencyRate,
+			ErrorRate:   c.Config.ChaosErrorRate,
+		}
+	}
+	return c.chaos
+}
+
+// Metrics returns the registry of the metrics served to Prometheus.
+func (c *Container) Metrics() *metrics.Registry {
+	if c.metrics == nil {
+		c.metrics = metrics.NewRegistry()
+	}
+	return c.metrics
+}
+
+// DB returns the database connection, connecting on first use. The caller is
+// responsible for closing it.
+func (c *Container) DB(ctx context.Context) (*database.DB, error) {
+	if c.db != nil {
+		return c.db, nil
+	}
+
+	db, err := database.Connect(ctx, c.Logger, c.Config)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.DB] failed to connect to database: %w", err)
+	}
+
+	if injector := c.Chaos(); injector != nil {
+		db.SetChaos(injector)
+	}
+	db.SetMetrics(c.Metrics())
+
+	c.db = db
+	return c.db, nil
+}
+
+// UsersService returns the users service.
+func (c *Container) UsersService(ctx context.Context) (*services.UsersService, error) {
+	if c.usersService != nil {
+		return c.usersService, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.UsersService] %w", err)
+	}
+
+	passwords, err := password.New(c.Config.PasswordHashCost)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.UsersService] %w", err)
+	}
+
+	keyring, err := c.Keyring()
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.UsersService] %w", err)
+	}
+
+	c.usersService = services.NewUsersService(c.Logger, db, passwords, keyring)
+	return c.usersService, nil
+}
+
+// EventsService returns the analytics events service.
+func (c *Container) EventsService(ctx context.Context) (*services.EventsService, error) {
+	if c.eventsService != nil {
+		return c.eventsService, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.EventsService] %w", err)
+	}
+
+	c.eventsService = services.NewEventsService(c.Logger, db, c.Config.EventsAnonymize)
+	return c.eventsService, nil
+}
+
+// SettingsService returns the blog settings service.
+func (c *Container) SettingsService(ctx context.Context) (*services.SettingsService, error) {
+	if c.settings != nil {
+		return c.settings, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.SettingsService] %w", err)
+	}
+
+	c.settings = services.NewSettingsService(c.Logger, db)
+	return c.settings, nil
+}
+
+// AnnouncementsService returns the announcements service.
+func (c *Container) AnnouncementsService(ctx context.Context) (*services.AnnouncementsService, error) {
+	if c.announcements != nil {
+		return c.announcements, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.AnnouncementsService] %w", err)
+	}
+
+	c.announcements = services.NewAnnouncementsService(c.Logger, db)
+	return c.announcements, nil
+}
+
+// PostsService returns the posts service.
+func (c *Container) PostsService(ctx context.Context) (*services.PostsService, error) {
+	if c.posts != nil {
+		return c.posts, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.PostsService] %w", err)
+	}
+
+	c.posts = services.NewPostsService(c.Logger, db)
+	return c.posts, nil
+}
+
+// CommentsService returns the comments service.
+func (c *Container) CommentsService(ctx context.Context) (*services.CommentsService, error) {
+	if c.comments != nil {
+		return c.comments, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.CommentsService] %w", err)
+	}
+
+	c.comments = services.NewCommentsService(c.Logger, db)
+	return c.comments, nil
+}
+
+// CSPReportsService returns the Content Security Policy report service.
+func (c *Container) CSPReportsService(ctx context.Context) (*services.CSPReportsService, error) {
+	if c.cspReports != nil {
+		return c.cspReports, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.CSPReportsService] %w", err)
+	}
+
+	c.cspReports = services.NewCSPReportsService(c.Logger, db)
+	return c.cspReports, nil
+}
+
+// CanariesService returns the canary users service.
+func (c *Container) CanariesService(ctx context.Context) (*services.CanariesService, error) {
+	if c.canaries != nil {
+		return c.canaries, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.CanariesService] %w", err)
+	}
+
+	usersService, err := c.UsersService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.CanariesService] %w", err)
+	}
+
+	c.canaries = services.NewCanariesService(c.Logger, db, usersService, c.Config.CanaryEmailDomain)
+	return c.canaries, nil
+}
+
+// DependenciesService returns the dependency version and feature detection
+// service.
+func (c *Container) DependenciesService(ctx context.Context) (*services.DependenciesService, error) {
+	if c.dependencies != nil {
+		return c.dependencies, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.DependenciesService] %w", err)
+	}
+
+	c.dependencies = services.NewDependenciesService(c.Logger, db, c.Clock)
+	return c.dependencies, nil
+}
+
+// Embedder returns the provider post and query embeddings are generated with,
+// or nil when embedding is disabled.
+func (c *Container) Embedder() embeddings.Provider {
+	if c.embedder == nil {
+		switch c.Config.EmbeddingProvider {
+		case config.ProviderOpenAI:
+			c.embedder = embeddings.NewOpenAI(
+				c.HTTPClient(),
+				c.Config.EmbeddingURL,
+				c.Config.EmbeddingModel,
+				c.Config.EmbeddingAPIKey,
+			)
+		case config.ProviderOllama:
+			c.embedder = embeddings.NewOllama(c.HTTPClient(), c.Config.EmbeddingURL, c.Config.EmbeddingModel)
+		}
+	}
+	return c.embedder
+}
+
+// EmbeddingWorker returns the background worker embedding posts, or nil when
+// embedding is disabled. It is not started.
+func (c *Container) EmbeddingWorker(ctx context.Context) (*embeddings.Worker, error) {
+	if c.embedWorker != nil || c.Embedder() == nil {
+		return c.embedWorker, nil
+	}
+
+	postsService, err := c.PostsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.EmbeddingWorker] %w", err)
+	}
+
+	c.embedWorker = embeddings.NewWorker(c.Logger, postsService, c.Embedder(), c.Config.EmbeddingBatchSize)
+	return c.embedWorker, nil
+}
+
+// LLM returns the language model provider, or nil when summaries are disabled.
+func (c *Container) LLM() llm.Provider {
+	if c.llm == nil {
+		switch c.Config.LLMProvider {
+		case config.ProviderOpenAI:
+			c.llm = llm.NewOpenAI(c.HTTPClient(), c.Config.LLMURL, c.Config.LLMModel, c.Config.LLMAPIKey)
+		case config.ProviderOllama:
+			c.llm = llm.NewOllama(c.HTTPClient(), c.Config.LLMURL, c.Config.LLMModel)
+		}
+	}
+	return c.llm
+}
+
+// SummariesService returns the post summaries service.
+func (c *Container) SummariesService(ctx context.Context) (*services.SummariesService, error) {
+	if c.summaries != nil {
+		return c.summaries, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.SummariesService] %w", err)
+	}
+
+	c.summaries = services.NewSummariesService(c.Logger, db, c.LLM())
+	return c.summaries, nil
+}
+
+// Linter returns the draft linter, which looks up linked posts and users.
+func (c *Container) Linter(ctx context.Context) (*lint.Linter, error) {
+	if c.linter != nil {
+		return c.linter, nil
+	}
+
+	postsService, err := c.PostsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Linter] %w", err)
+	}
+
+	usersService, err := c.UsersService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Linter] %w", err)
+	}
+
+	c.linter = lint.New(postsService, usersService, c.Config.ClientOrigin, c.Config.LintBudget, c.Config.LintMaxParagraphWords)
+	return c.linter, nil
+}
+
+// SLOTracker returns the in-memory tracker of request outcomes.
+func (c *Container) SLOTracker() *slo.Tracker {
+	if c.sloTracker == nil {
+		c.sloTracker = slo.NewTracker(c.Clock, slo.Objectives{
+			Availability: c.Config.SLOAvailabilityTarget,
+			Latency:      c.Config.SLOLatencyTarget,
+		})
+	}
+	return c.sloTracker
+}
+
+// HealthMonitor returns the dependency health monitor. It is not started.
+func (c *Container) HealthMonitor(ctx context.Context) (*health.Monitor, error) {
+	if c.healthMonitor != nil {
+		return c.healthMonitor, nil
+	}
+
+	db, err := c.DB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.HealthMonitor] %w", err)
+	}
+
+	c.healthMonitor = health.NewMonitor(
+		c.Logger,
+		c.Clock,
+		c.Config.HealthHistorySize,
+		c.Config.HealthCheckTimeout,
+		map[string]health.Checker{
+			"database": health.CheckerFunc(db.PingContext),
+		},
+	)
+	return c.healthMonitor, nil
+}
+
+// HTTPClient returns the outbound http client shared by integrations.
+func (c *Container) HTTPClient() *httpclient.Client {
+	if c.httpClient == nil {
+		c.httpClient = httpclient.New(httpclient.Options{
+			Timeout:          c.Config.HTTPClientTimeout,
+			MaxConnsPerHost:  c.Config.HTTPClientMaxConnsPerHost,
+			FailureThreshold: c.Config.HTTPClientFailureThreshold,
+			Cooldown:         c.Config.HTTPClientCooldown,
+			Clock:            c.Clock,
+		})
+	}
+	return c.httpClient
+}
+
+// RealIP returns the resolver of client addresses behind the trusted proxies.
+func (c *Container) RealIP() (*realip.Resolver, error) {
+	if c.realIP != nil {
+		return c.realIP, nil
+	}
+
+	resolver, err := realip.New(c.Config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.RealIP] %w", err)
+	}
+
+	c.realIP = resolver
+	return c.realIP, nil
+}
+
+// Shedder returns the overload detector deciding which requests to shed. It is
+// not started.
+func (c *Container) Shedder() *overload.Shedder {
+	if c.shedder == nil {
+		c.shedder = overload.NewShedder(overload.Limits{
+			MaxInFlight: c.Config.LoadShedMaxInFlight,
+			MaxLag:      c.Config.LoadShedMaxLag,
+		})
+	}
+	return c.shedder
+}
+
+// JSONDecoding returns how strictly each route group decodes request bodies.
+func (c *Container) JSONDecoding() middleare.JSONDecoding {
+	strict := make(map[string]bool, len(c.Config.JSONDecoding))
+	for group, mode := range c.Config.JSONDecoding {
+		strict[group] = mode == config.JSONDecodingStrict
+	}
+
+	return middleare.JSONDecoding{
+		Strict:  strict,
+		Default: c.Config.JSONDecodingDefault == config.JSONDecodingStrict,
+	}
+}
+
+// RateLimits returns the per-client rate limits of the route groups.
+func (c *Container) RateLimits() (middleare.RateLimits, error) {
+	limits := make(map[string]ratelimit.Limit, len(c.Config.RateLimits))
+	for group, value := range c.Config.RateLimits {
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			return middleare.RateLimits{}, fmt.Errorf("[in deps.Container.RateLimits] group %q: %w", group, err)
+		}
+		limits[group] = limit
+	}
+
+	tokens, err := c.Tokens()
+	if err != nil {
+		return middleare.RateLimits{}, fmt.Errorf("[in deps.Container.RateLimits] %w", err)
+	}
+
+	securityEvents, err := c.SecurityEvents()
+	if err != nil {
+		return middleare.RateLimits{}, fmt.Errorf("[in deps.Container.RateLimits] %w", err)
+	}
+
+	if c.rateLimits == nil {
+		c.rateLimits = ratelimit.NewMemoryStore(c.Clock)
+	}
+
+	return middleare.RateLimits{
+		Logger: c.Logger,
+		Store:  c.rateLimits,
+		Limits: limits,
+		Tokens: tokens,
+		Events: securityEvents,
+	}, nil
+}
+
+// LatencyBudgets returns the per-route latency budgets used to flag slow
+// requests.
+func (c *Container) LatencyBudgets() middleare.LatencyBudgets {
+	return middleare.LatencyBudgets{
+		Default: c.Config.LatencyBudgetDefault,
+		Routes:  c.Config.LatencyBudgets,
+	}
+}
+
+// Tokens returns the issuer and verifier of bearer tokens.
+func (c *Container) Tokens() (*auth.Tokens, error) {
+	if c.tokens != nil {
+		return c.tokens, nil
+	}
+
+	activeFrom := make(map[string]time.Time, len(c.Config.JWTKeyActivation))
+	for id, value := range c.Config.JWTKeyActivation {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("[in deps.Container.Tokens] failed to parse activation time of key %q: %w", id, err)
+		}
+		activeFrom[id] = at
+	}
+
+	var keys []auth.Key
+	if c.Config.JWTSecret != "" {
+		key, err := auth.HMACKey(
+			config.DefaultJWTKeyID,
+			c.Config.JWTAlgorithm,
+			[]byte(c.Config.JWTSecret),
+			activeFrom[config.DefaultJWTKeyID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("[in deps.Container.Tokens] %w", err)
+		}
+		keys = append(keys, key)
+	}
+	for id, path := range c.Config.JWTKeyFiles {
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("[in deps.Container.Tokens] failed to read key %q: %w", id, err)
+		}
+		key, err := auth.ParsePrivateKey(id, pemData, activeFrom[id])
+		if err != nil {
+			return nil, fmt.Errorf("[in deps.Container.Tokens] %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	tokens, err := auth.New(c.Clock, c.Config.JWTIssuer, c.Config.JWTTTL, c.Config.JWTKeyOverlap, keys)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Tokens] %w", err)
+	}
+
+	c.tokens = tokens
+	return c.tokens, nil
+}
+
+// SecurityEvents returns the emitter of security events, which alerts the
+// configured webhook through the shared http client.
+func (c *Container) SecurityEvents() (*events.Emitter, error) {
+	if c.securityEvents != nil {
+		return c.securityEvents, nil
+	}
+
+	severity, err := events.ParseSeverity(c.Config.SecurityAlertSeverity)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.SecurityEvents] %w", err)
+	}
+
+	logger := c.SecurityLogger
+	if logger == nil {
+		logger = c.Logger
+	}
+
+	logger = logger.With(slog.String("log_stream", "security"))
+
+	c.securityEvents = events.NewEmitter(logger, c.Clock, c.HTTPClient(), events.Options{
+		WebhookURL:    c.Config.SecurityAlertWebhookURL,
+		AlertSeverity: severity,
+		AlertThrottle: c.Config.SecurityAlertThrottle,
+	})
+	return c.securityEvents, nil
+}
+
+// Keyring returns the keys sensitive columns are encrypted with.
+func (c *Container) Keyring() (*crypto.Keyring, error) {
+	if c.keyring != nil {
+		return c.keyring, nil
+	}
+
+	keys := make(map[string][]byte, len(c.Config.EncryptionKeys))
+	for id, encoded := range c.Config.EncryptionKeys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("[in deps.Container.Keyring] failed to decode key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	indexKey, err := base64.StdEncoding.DecodeString(c.Config.EncryptionIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Keyring] failed to decode index key: %w", err)
+	}
+
+	keyring, err := crypto.NewKeyring(keys, c.Config.EncryptionCurrentKey, indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Keyring] %w", err)
+	}
+
+	c.keyring = keyring
+	return c.keyring, nil
+}
+
+// csrfSameSite maps the values of CSRF_COOKIE_SAME_SITE to cookie attributes.
+var csrfSameSite = map[string]http.SameSite{
+	"strict": http.SameSiteStrictMode,
+	"lax":    http.SameSiteLaxMode,
+	"none":   http.SameSiteNoneMode,
+}
+
+// Handler returns every API route wrapped in the chaos, SLO and metrics
+// middleware. Access logging is left to the caller, as its output depends on
+// where the handler is served from.
+func (c *Container) Handler(ctx context.Context) (http.Handler, error) {
+	usersService, err := c.UsersService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	eventsService, err := c.EventsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	settingsService, err := c.SettingsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	announcementsService, err := c.AnnouncementsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	postsService, err := c.PostsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	commentsService, err := c.CommentsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	cspReportsService, err := c.CSPReportsService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	canariesService, err := c.CanariesService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	dependenciesService, err := c.DependenciesService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	summariesService, err := c.SummariesService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	linter, err := c.Linter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	healthMonitor, err := c.HealthMonitor(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	tokens, err := c.Tokens()
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	securityEvents, err := c.SecurityEvents()
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	rateLimits, err := c.RateLimits()
+	if err != nil {
+		return nil, fmt.Errorf("[in deps.Container.Handler] %w", err)
+	}
+
+	mux := http.NewServeMux()
+	handler := routes.AddRoutes(mux, c.Logger, usersService, eventsService, settingsService, announcementsService, postsService, commentsService, cspReportsService, canariesService, dependenciesService, summariesService, c.SLOTracker(), healthMonitor, routes.Options{
+		HealthzCacheTTL:       c.Config.HealthzCacheTTL,
+		AnnouncementsCacheTTL: c.Config.AnnouncementsCacheTTL,
+		Bulkheads: middleare.Bulkheads{
+			Logger:       c.Logger,
+			Limits:       c.Config.BulkheadLimits,
+			QueueTimeout: c.Config.BulkheadQueueTimeout,
+		},
+		RateLimits:   rateLimits,
+		JSONDecoding: c.JSONDecoding(),
+		Shedder:      c.Shedder(),
+		Tokens:       tokens,
+		AdminUserIDs: c.Config.AdminUserIDs,
+		CSRF: middleare.CSRF{
+			Logger:   c.Logger,
+			Secure:   c.Config.CSRFCookieSecure,
+			SameSite: csrfSameSite[c.Config.CSRFCookieSameSite],
+			Events:   securityEvents,
+		},
+		CORS: middleare.CORS{
+			AllowedOrigins:   c.Config.CORSAllowedOrigins,
+			AllowedMethods:   c.Config.CORSAllowedMethods,
+			AllowedHeaders:   c.Config.CORSAllowedHeaders,
+			ExposedHeaders:   c.Config.CORSExposedHeaders,
+			AllowCredentials: c.Config.CORSAllowCredentials,
+			MaxAge:           c.Config.CORSMaxAge,
+		},
+		SecurityEvents: securityEvents,
+		Metrics:        c.Metrics(),
+		Embedder:       c.Embedder(),
+		Linter:         linter,
+	})
+
+	if injector := c.Chaos(); injector != nil {
+		handler = middleare.Chaos(c.Logger, injector)(handler)
+	}
+	handler = middleare.SLO(c.SLOTracker(), c.LatencyBudgets())(handler)
+	handler = middleare.Metrics(c.Metrics(), mux)(handler)
+
+	return handler, nil
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// defaultTimeout is used for hooks that do not set their own timeout.
+const defaultTimeout = 10 * time.Second
+
+// Hook is a component of the application that is started and stopped with it,
+// such as a connection pool, server, or background worker.
+type Hook struct {
+	// Name identifies the component in logs and errors.
+	Name string
+
+	// Start starts the component. It must not block; long-running work should
+	// be started in a goroutine, reporting unexpected failures with
+	// Lifecycle.Fail. Optional.
+	Start func(ctx context.Context) error
+
+	// Stop stops the component, releasing its resources. Optional.
+	Stop func(ctx context.Context) error
+
+	// StartTimeout and StopTimeout bound how long Start and Stop may take.
+	// Default to 10 seconds.
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+}
+
+// Lifecycle starts hooks in the order they were appended and stops them in
+// reverse order, so a component is always stopped before the components it
+// depends on.
+type Lifecycle struct {
+	logger  *slog.Logger
+	hooks   []Hook
+	started int
+	errs    chan error
+}
+
+// New creates a new Lifecycle and returns a pointer to it.
+func New(logger *slog.Logger) *Lifecycle {
+	return &Lifecycle{
+		logger: logger,
+		errs:   make(chan error, 1),
+	}
+}
+
+// Append adds a hook to the lifecycle. Hooks must be appended before Start is
+// called.
+func (l *Lifecycle) Append(hook Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+// Fail reports an unexpected failure of a running component, causing Run to
+// stop the application and return the error. Only the first failure is kept.
+func (l *Lifecycle) Fail(err error) {
+	select {
+	case l.errs <- err:
+	default:
+	}
+}
+
+// Start starts every hook in order. If a hook fails to start, the hooks
+// already started are stopped and the start error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, hook := range l.hooks[l.started:] {
+		if hook.Start != nil {
+			l.logger.DebugContext(ctx, "Starting component", slog.String("component", hook.Name))
+
+			if err := l.run(ctx, hook.Start, hook.StartTimeout); err != nil {
+				err = fmt.Errorf("[in app.Lifecycle.Start] failed to start %s: %w", hook.Name, err)
+				return errors.Join(err, l.Stop(ctx))
+			}
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Stop stops every started hook in reverse order. All hooks are stopped even
+// if some fail, and their errors are joined.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	// Stop runs after the run context is usually cancelled, so timeouts are
+	// derived from a context that is not.
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		hook := l.hooks[l.started-1]
+		if hook.Stop == nil {
+			continue
+		}
+
+		l.logger.DebugContext(ctx, "Stopping component", slog.String("component", hook.Name))
+
+		if err := l.run(ctx, hook.Stop, hook.StopTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("[in app.Lifecycle.Stop] failed to stop %s: %w", hook.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run starts every hook, blocks until ctx is done, an interrupt or terminate
+// signal is received, or a component fails, then stops every hook.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var runErr error
+	select {
+	case <-sigCtx.Done():
+		l.logger.DebugContext(ctx, "Received signal, shutting down")
+	case runErr = <-l.errs:
+		l.logger.ErrorContext(ctx, "Component failed, shutting down", slog.String("error", runErr.Error()))
+	}
+
+	return errors.Join(runErr, l.Stop(ctx))
+}
+
+// run calls fn with a context bounded by timeout.
+func (l *Lifecycle) run(ctx context.Context, fn func(ctx context.Context) error, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return fn(ctx)
+}
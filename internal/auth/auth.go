@@ -0,0 +1,211 @@
+// Package auth issues and verifies the signed tokens that identify the user
+// making a request.
+package auth
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// ErrInvalidToken is returned when a token is malformed, has been tampered
+// with, or has expired.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Tokens issues and verifies JSON Web Tokens. The subject of a token is the id
+// of the user it was issued to.
+//
+// Tokens are signed with the most recently activated key, or of the keys
+// activated at the same time, the one with the greatest id. When a new key
+// becomes active, tokens signed with the key before it are accepted for the
+// overlap that follows, which should be at least the token lifetime so that
+// tokens issued just before the rotation stay valid until they expire.
+type Tokens struct {
+	clock   clock.Clock
+	issuer  string
+	ttl     time.Duration
+	overlap time.Duration
+	// keys are ordered by ActiveFrom, then by ID.
+	keys []Key
+}
+
+// New creates a new Tokens using the provided keys and returns a pointer to
+// it. Tokens expire ttl after they are issued.
+func New(clk clock.Clock, issuer string, ttl time.Duration, overlap time.Duration, keys []Key) (*Tokens, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("[in auth.New] at least one key is required")
+	}
+
+	// Keys activated at the same time are ordered by id, so the key tokens are
+	// signed with does not depend on the order keys were configured in, which
+	// is random for keys read from a map.
+	keys = slices.Clone(keys)
+	slices.SortFunc(keys, func(a, b Key) int {
+		return cmp.Or(a.ActiveFrom.Compare(b.ActiveFrom), strings.Compare(a.ID, b.ID))
+	})
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.ID] {
+			return nil, fmt.Errorf("[in auth.New] duplicate key id %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	return &Tokens{
+		clock:   clk,
+		issuer:  issuer,
+		ttl:     ttl,
+		overlap: overlap,
+		keys:    keys,
+	}, nil
+}
+
+// current returns the index of the key tokens are signed with at now, or -1
+// if no key is active yet.
+func (t *Tokens) current(now time.Time) int {
+	current := -1
+	for i, key := range t.keys {
+		if key.ActiveFrom.After(now) {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// Issue returns a signed token for the user with the provided id, along with
+// the time it expires.
+func (t *Tokens) Issue(userID uint) (string, time.Time, error) {
+	now := t.clock.Now()
+	expiresAt := now.Add(t.ttl)
+
+	current := t.current(now)
+	if current < 0 {
+		return "", time.Time{}, fmt.Errorf("[in auth.Tokens.Issue] no signing key is active yet")
+	}
+	key := t.keys[current]
+
+	token := jwt.NewWithClaims(key.method, jwt.RegisteredClaims{
+		Issuer:    t.issuer,
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+	token.Header["kid"] = key.ID
+
+	signed, err := token.SignedString(key.signing)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("[in auth.Tokens.Issue] failed to sign token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature, issuer and expiry of the provided token and
+// returns the id of the user it was issued to, or ErrInvalidToken. Tokens
+// must name the key they were signed with, which must be the current key or
+// one replaced less than the overlap ago.
+func (t *Tokens) Verify(token string) (uint, error) {
+	now := t.clock.Now()
+
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := t.accepted(kid, now)
+			if !ok {
+				return nil, fmt.Errorf("key %q is not accepted", kid)
+			}
+			// Pinning the algorithm to the key stops a token from choosing how
+			// it is checked.
+			if token.Method.Alg() != key.method.Alg() {
+				return nil, fmt.Errorf("algorithm %q does not match key %q", token.Method.Alg(), kid)
+			}
+			return key.verifying(), nil
+		},
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(t.clock.Now),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("[in auth.Tokens.Verify] %w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("[in auth.Tokens.Verify] %w: subject %q is not a user id", ErrInvalidToken, claims.Subject)
+	}
+
+	return uint(userID), nil
+}
+
+// accepted returns the key with the provided id if tokens signed with it are
+// accepted at now.
+func (t *Tokens) accepted(id string, now time.Time) (Key, bool) {
+	current := t.current(now)
+	for i, key := range t.keys {
+		if key.ID != id {
+			continue
+		}
+		switch {
+		case i > current:
+			return Key{}, false
+		case i < current:
+			// A replaced key is accepted until the overlap after the key that
+			// replaced it became active has passed.
+			return key, now.Before(t.keys[i+1].ActiveFrom.Add(t.overlap))
+		default:
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// JWKS returns the public keys verifiers need to check tokens, in JSON Web Key
+// Set format. Keys that are not active yet are included so verifiers can fetch
+// them before the rotation, and replaced keys until their overlap has passed.
+// HMAC keys are secret and never included.
+func (t *Tokens) JWKS() JWKSet {
+	now := t.clock.Now()
+	current := t.current(now)
+
+	set := JWKSet{Keys: make([]JWK, 0, len(t.keys))}
+	for i, key := range t.keys {
+		if i < current && !now.Before(t.keys[i+1].ActiveFrom.Add(t.overlap)) {
+			continue
+		}
+		if jwk, ok := key.jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// contextKey is the key the authenticated user id is stored under in a
+// context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the id of the authenticated user.
+func NewContext(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserIDFromContext returns the id of the authenticated user stored in ctx, if
+// any.
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(contextKey{}).(uint)
+	return userID, ok
+}
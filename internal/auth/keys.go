@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a key tokens are signed with from ActiveFrom until the next key
+// becomes active, and verified with until the overlap after that has passed.
+type Key struct {
+	// ID is sent as the kid header of tokens, so verifiers know which key to
+	// check them with.
+	ID         string
+	ActiveFrom time.Time
+
+	method  jwt.SigningMethod
+	signing any
+	// public is nil for HMAC keys, which are secret and never published.
+	public crypto.PublicKey
+}
+
+// HMACKey creates a Key signing with the provided shared secret and HMAC
+// algorithm, "HS256", "HS384" or "HS512".
+func HMACKey(id string, algorithm string, secret []byte, activeFrom time.Time) (Key, error) {
+	method, ok := jwt.GetSigningMethod(algorithm).(*jwt.SigningMethodHMAC)
+	if !ok {
+		return Key{}, fmt.Errorf("[in auth.HMACKey] unsupported algorithm %q", algorithm)
+	}
+	if len(secret) < 32 {
+		return Key{}, fmt.Errorf("[in auth.HMACKey] secret must be at least 32 bytes")
+	}
+
+	return Key{ID: id, ActiveFrom: activeFrom, method: method, signing: secret}, nil
+}
+
+// ParsePrivateKey creates a Key from a PEM encoded PKCS #8 private key. Ed25519
+// keys sign with EdDSA, P-256 keys with ES256, and RSA keys of at least 2048
+// bits with RS256.
+func ParsePrivateKey(id string, pemData []byte, activeFrom time.Time) (Key, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return Key{}, fmt.Errorf("[in auth.ParsePrivateKey] key %q is not PEM encoded", id)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return Key{}, fmt.Errorf("[in auth.ParsePrivateKey] key %q: %w", id, err)
+	}
+
+	key := Key{ID: id, ActiveFrom: activeFrom, signing: parsed}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.public = private.Public()
+	case *ecdsa.PrivateKey:
+		if private.Curve != elliptic.P256() {
+			return Key{}, fmt.Errorf("[in auth.ParsePrivateKey] key %q: only P-256 ECDSA keys are supported", id)
+		}
+		key.method = jwt.SigningMethodES256
+		key.public = &private.PublicKey
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < 2048 {
+			return Key{}, fmt.Errorf("[in auth.ParsePrivateKey] key %q: RSA keys must be at least 2048 bits", id)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.public = &private.PublicKey
+	default:
+		return Key{}, fmt.Errorf("[in auth.ParsePrivateKey] key %q: unsupported key type %T", id, parsed)
+	}
+
+	return key, nil
+}
+
+// verifying returns the key tokens signed with k are checked with.
+func (k Key) verifying() any {
+	if k.public == nil {
+		return k.signing
+	}
+	return k.public
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType string `json:"kty"`
+	ID      string `json:"kid"`
+	Use     string `json:"use"`
+	Alg     string `json:"alg"`
+	Curve   string `json:"crv,omitempty"`
+	X       string `json:"x,omitempty"`
+	Y       string `json:"y,omitempty"`
+	N       string `json:"n,omitempty"`
+	E       string `json:"e,omitempty"`
+}
+
+// JWKSet is a set of public keys in JSON Web Key Set format.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk returns the public part of k as a JWK, or false for HMAC keys.
+func (k Key) jwk() (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{ID: k.ID, Use: "sig", Alg: k.method.Alg()}
+
+	switch public := k.public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", b64(public)
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the curve size, as JWK requires.
+		x, y := make([]byte, 32), make([]byte, 32)
+		public.X.FillBytes(x)
+		public.Y.FillBytes(y)
+		jwk.KeyType, jwk.Curve, jwk.X, jwk.Y = "EC", "P-256", b64(x), b64(y)
+	case *rsa.PublicKey:
+		jwk.KeyType, jwk.N, jwk.E = "RSA", b64(public.N.Bytes()), b64(big.NewInt(int64(public.E)).Bytes())
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
@@ -0,0 +1,55 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrInjected is returned when an Injector decides to fail an operation.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Injector injects latency and errors into operations at configured rates. It
+// is used to validate retries, timeouts and circuit breakers, and must never
+// be enabled in production. A nil *Injector injects nothing.
+type Injector struct {
+	// Latency is the delay added to an operation selected for latency.
+	Latency time.Duration
+	// LatencyRate is the fraction of operations, between 0 and 1, that are
+	// delayed.
+	LatencyRate float64
+	// ErrorRate is the fraction of operations, between 0 and 1, that fail.
+	ErrorRate float64
+}
+
+// Delay sleeps for the configured latency if the operation is selected, or
+// until ctx is done.
+func (i *Injector) Delay(ctx context.Context) {
+	if i == nil || i.Latency <= 0 || rand.Float64() >= i.LatencyRate {
+		return
+	}
+
+	timer := time.NewTimer(i.Latency)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Fail reports whether the operation is selected to fail.
+func (i *Injector) Fail() bool {
+	return i != nil && rand.Float64() < i.ErrorRate
+}
+
+// Inject delays the operation if selected, then returns ErrInjected if it is
+// selected to fail.
+func (i *Injector) Inject(ctx context.Context) error {
+	i.Delay(ctx)
+	if i.Fail() {
+		return ErrInjected
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time. Components with time dependent behavior,
+// such as expirations and schedules, take a Clock so that tests can control
+// time instead of sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is a Clock backed by the system clock.
+type Real struct{}
+
+// Now returns the current system time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a Clock whose time only changes when it is told to. The zero value
+// starts at the zero time.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake creates a new Fake set to now and returns a pointer to it.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Advance moves the fake's time forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+// Set changes the fake's time to now.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+}
@@ -3,23 +3,300 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
 
+// Supported values for the DB_DRIVER environment variable.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Supported values for the LOG_OUTPUT, ACCESS_LOG_OUTPUT and
+// SECURITY_LOG_OUTPUT environment variables.
+const (
+	LogOutputStdout = "stdout"
+	LogOutputFile   = "file"
+	LogOutputSyslog = "syslog"
+)
+
+// Supported values for the LAMBDA_EVENT_SOURCE environment variable.
+const (
+	LambdaEventAPIGateway   = "apigateway"
+	LambdaEventAPIGatewayV2 = "apigatewayv2"
+	LambdaEventALB          = "alb"
+)
+
+// Supported values for the EMBEDDING_PROVIDER and LLM_PROVIDER environment
+// variables.
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+// Supported values for the JSON_DECODING and JSON_DECODING_DEFAULT environment
+// variables.
+const (
+	JSONDecodingStrict  = "strict"
+	JSONDecodingLenient = "lenient"
+)
+
+// providerURLs are the addresses providers are reached at when no URL is
+// configured.
+var providerURLs = map[string]string{
+	ProviderOpenAI: "https://api.openai.com/v1",
+	ProviderOllama: "http://localhost:11434",
+}
+
+// DefaultJWTKeyID is the key id tokens signed with JWT_SECRET are issued
+// under.
+const DefaultJWTKeyID = "default"
+
+// LogSink holds the settings of a single log output target. The same settings
+// are read for the application logger with a LOG_ prefix, for the access
+// logger with an ACCESS_LOG_ prefix and for the security event logger with a
+// SECURITY_LOG_ prefix.
+type LogSink struct {
+	Output string `env:"OUTPUT" envDefault:"stdout"`
+
+	// Rotating file settings, used when Output is "file". Files are rotated
+	// once they reach FileMaxSize bytes or FileMaxAge, whichever comes first.
+	FilePath       string        `env:"FILE_PATH"`
+	FileMaxSize    int64         `env:"FILE_MAX_SIZE" envDefault:"104857600"`
+	FileMaxAge     time.Duration `env:"FILE_MAX_AGE" envDefault:"24h"`
+	FileMaxBackups int           `env:"FILE_MAX_BACKUPS" envDefault:"7"`
+
+	// Syslog settings, used when Output is "syslog". An empty address logs to
+	// the local syslog daemon.
+	SyslogNetwork string `env:"SYSLOG_NETWORK"`
+	SyslogAddress string `env:"SYSLOG_ADDRESS"`
+	SyslogTag     string `env:"SYSLOG_TAG" envDefault:"blog"`
+}
+
 // Config holds the application configuration settings. The configuration is loaded from
 // environment variables.
 type Config struct {
-	DBHost         string     `env:"DATABASE_HOST,required"`
-	DBUserName     string     `env:"DATABASE_USER,required"`
-	DBUserPassword string     `env:"DATABASE_PASSWORD,required"`
-	DBName         string     `env:"DATABASE_NAME,required"`
-	DBPort         string     `env:"DATABASE_PORT,required"`
-	ClientOrigin   string     `env:"CLIENT_ORIGIN,required"`
-	Host           string     `env:"HOST,required"`
-	Port           string     `env:"PORT,required"`
-	LogLevel       slog.Level `env:"LOG_LEVEL,required"`
+	DBDriver       string `env:"DB_DRIVER" envDefault:"postgres"`
+	DBHost         string `env:"DATABASE_HOST"`
+	DBUserName     string `env:"DATABASE_USER"`
+	DBUserPassword string `env:"DATABASE_PASSWORD"`
+	DBName         string `env:"DATABASE_NAME,required"`
+	DBPort         string `env:"DATABASE_PORT"`
+
+	// Connection pool settings. DBRebuildAfterErrors is the number of
+	// consecutive connection errors after which the pool is rebuilt, zero
+	// disables rebuilding.
+	DBConnMaxLifetime    time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"5m"`
+	DBConnMaxIdleTime    time.Duration `env:"DB_CONN_MAX_IDLE_TIME" envDefault:"1m"`
+	DBRebuildAfterErrors int           `env:"DB_REBUILD_AFTER_ERRORS" envDefault:"0"`
+
+	ClientOrigin string     `env:"CLIENT_ORIGIN,required"`
+	Host         string     `env:"HOST,required"`
+	Port         string     `env:"PORT,required"`
+	LogLevel     slog.Level `env:"LOG_LEVEL,required"`
+
+	// Certificate and key files used to serve HTTPS. The server uses plain
+	// HTTP when neither is set.
+	TLSCertFile string `env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE"`
+
+	// HTTP3Enabled serves HTTP/3 over UDP on the same port as the TCP listener,
+	// advertised to clients with an Alt-Svc header. Requires TLS.
+	HTTP3Enabled bool `env:"HTTP3_ENABLED" envDefault:"false"`
+
+	// Signing of the bearer tokens issued at login. JWT_SECRET is an HMAC
+	// secret of at least 32 bytes used with JWTAlgorithm, "HS256", "HS384" or
+	// "HS512", under the key id "default". JWT_KEY_FILES lists PEM encoded
+	// PKCS #8 private keys by key id, e.g. "2025a=/keys/a.pem,2025b=/keys/b.pem",
+	// whose public keys are served at /.well-known/jwks.json. At least one of
+	// the two is required.
+	JWTSecret    string            `env:"JWT_SECRET"`
+	JWTAlgorithm string            `env:"JWT_ALGORITHM" envDefault:"HS256"`
+	JWTKeyFiles  map[string]string `env:"JWT_KEY_FILES" envKeyValSeparator:"="`
+	JWTIssuer    string            `env:"JWT_ISSUER" envDefault:"blog"`
+	JWTTTL       time.Duration     `env:"JWT_TTL" envDefault:"1h"`
+
+	// Rotation schedule of the signing keys. JWT_KEY_ACTIVATION gives the
+	// RFC 3339 time each key id starts signing tokens, e.g.
+	// "2025b=2025-06-01T00:00:00Z", and keys without one are active from the
+	// start. Tokens signed with a replaced key are accepted for JWTKeyOverlap
+	// after its replacement becomes active, which should be at least JWTTTL.
+	JWTKeyActivation map[string]string `env:"JWT_KEY_ACTIVATION" envKeyValSeparator:"="`
+	JWTKeyOverlap    time.Duration     `env:"JWT_KEY_OVERLAP" envDefault:"1h"`
+
+	// AdminUserIDs lists the ids of the users allowed to use the admin routes,
+	// e.g. "1,42". The admin routes that change state are unusable when empty.
+	AdminUserIDs []uint `env:"ADMIN_USER_IDS"`
+
+	// CSRFCookieSecure only sends the CSRF cookie over HTTPS. Disable it for
+	// local development of browser clients over plain HTTP.
+	// CSRFCookieSameSite is "strict", "lax" or "none". Browser clients served
+	// from another site than the API need "none", which requires
+	// CSRF_COOKIE_SECURE, along with CORS_ALLOW_CREDENTIALS.
+	CSRFCookieSecure   bool   `env:"CSRF_COOKIE_SECURE" envDefault:"true"`
+	CSRFCookieSameSite string `env:"CSRF_COOKIE_SAME_SITE" envDefault:"strict"`
+
+	// Encryption of sensitive columns at rest. ENCRYPTION_KEYS lists base64
+	// encoded 32 byte AES keys by id, e.g. "2024a:<key>,2025a:<key>", and new
+	// values are encrypted with the key named by ENCRYPTION_CURRENT_KEY.
+	// ENCRYPTION_INDEX_KEY, also base64 encoded, derives the blind indexes used
+	// to look up encrypted values and must not change once data is stored.
+	EncryptionKeys       map[string]string `env:"ENCRYPTION_KEYS,required"`
+	EncryptionCurrentKey string            `env:"ENCRYPTION_CURRENT_KEY,required"`
+	EncryptionIndexKey   string            `env:"ENCRYPTION_INDEX_KEY,required"`
+
+	// PasswordHashCost is the bcrypt cost passwords are hashed with. Each
+	// increment doubles the time taken to hash and check a password.
+	PasswordHashCost int `env:"PASSWORD_HASH_COST" envDefault:"12"`
+
+	// AccessLogFormat selects how requests are logged, "json" for structured
+	// records or "clf" for the Apache Combined Log Format.
+	AccessLogFormat string `env:"ACCESS_LOG_FORMAT" envDefault:"json"`
+
+	// TracingEnabled adds the current trace and span ids to every log record.
+	TracingEnabled bool `env:"TRACING_ENABLED" envDefault:"false"`
+
+	// Output targets for the application and access loggers.
+	AppLog    LogSink `envPrefix:"LOG_"`
+	AccessLog LogSink `envPrefix:"ACCESS_LOG_"`
+
+	// Security events, such as failed logins and permission denials, are
+	// logged to their own output so they can be retained and watched
+	// separately. Events of SecurityAlertSeverity, "info", "warning" or
+	// "critical", and above are also posted to SECURITY_ALERT_WEBHOOK_URL, at
+	// most once per event type every SecurityAlertThrottle.
+	SecurityLog             LogSink       `envPrefix:"SECURITY_LOG_"`
+	SecurityAlertWebhookURL string        `env:"SECURITY_ALERT_WEBHOOK_URL"`
+	SecurityAlertSeverity   string        `env:"SECURITY_ALERT_SEVERITY" envDefault:"warning"`
+	SecurityAlertThrottle   time.Duration `env:"SECURITY_ALERT_THROTTLE" envDefault:"5m"`
+
+	// CanaryEmailDomain is the domain of the email addresses generated for
+	// canary users, which should look like any other user's.
+	CanaryEmailDomain string `env:"CANARY_EMAIL_DOMAIN" envDefault:"example.com"`
+
+	// Embeddings of posts, generated in the background for semantic search.
+	// EMBEDDING_PROVIDER is "openai" for the OpenAI API or any server
+	// compatible with it, "ollama" for a local Ollama server, or empty to
+	// disable embedding. EMBEDDING_URL defaults to the provider's usual
+	// address. Posts created or updated since they were last embedded are
+	// embedded every EmbeddingInterval, EmbeddingBatchSize per provider call.
+	EmbeddingProvider  string        `env:"EMBEDDING_PROVIDER"`
+	EmbeddingURL       string        `env:"EMBEDDING_URL"`
+	EmbeddingModel     string        `env:"EMBEDDING_MODEL"`
+	EmbeddingAPIKey    string        `env:"EMBEDDING_API_KEY"`
+	EmbeddingInterval  time.Duration `env:"EMBEDDING_INTERVAL" envDefault:"1m"`
+	EmbeddingBatchSize int           `env:"EMBEDDING_BATCH_SIZE" envDefault:"16"`
+
+	// Language model used to summarize posts. LLM_PROVIDER takes the same
+	// values as EMBEDDING_PROVIDER, and empty disables summaries.
+	LLMProvider string `env:"LLM_PROVIDER"`
+	LLMURL      string `env:"LLM_URL"`
+	LLMModel    string `env:"LLM_MODEL"`
+	LLMAPIKey   string `env:"LLM_API_KEY"`
+
+	// Draft linting. Paragraphs longer than LINT_MAX_PARAGRAPH_WORDS are
+	// reported, and LINT_BUDGET bounds the time spent looking up the posts and
+	// users a draft links to, after which the findings so far are returned.
+	LintBudget            time.Duration `env:"LINT_BUDGET" envDefault:"2s"`
+	LintMaxParagraphWords int           `env:"LINT_MAX_PARAGRAPH_WORDS" envDefault:"150"`
+
+	// IDWorkerID identifies this process to the id generator. Every running
+	// instance must use a different value between 0 and 1023.
+	IDWorkerID int `env:"ID_WORKER_ID" envDefault:"0"`
+
+	// Latency budgets used to flag slow requests. LATENCY_BUDGETS is a comma
+	// separated list of route pattern and duration pairs, e.g.
+	// "GET /api/users/{id}:200ms,GET /api/users:500ms".
+	LatencyBudgetDefault time.Duration            `env:"LATENCY_BUDGET_DEFAULT" envDefault:"1s"`
+	LatencyBudgets       map[string]time.Duration `env:"LATENCY_BUDGETS"`
+
+	// Maximum concurrent requests per route group, "users", "posts", "events"
+	// or "admin", e.g. "users:64,admin:4". Requests wait up to
+	// BulkheadQueueTimeout for a free slot before being shed with a 503.
+	BulkheadLimits       map[string]int `env:"BULKHEAD_LIMITS"`
+	BulkheadQueueTimeout time.Duration  `env:"BULKHEAD_QUEUE_TIMEOUT" envDefault:"100ms"`
+
+	// Requests each client may make per route group, as requests/duration,
+	// e.g. "users:100/1m,admin:20/1m". Logged in users are counted by user and
+	// others by client address. Limits are tracked by each instance on its
+	// own.
+	RateLimits map[string]string `env:"RATE_LIMITS"`
+
+	// How strictly each route group decodes JSON request bodies, "strict" to
+	// reject unknown fields or "lenient" to ignore them, e.g. "admin:strict".
+	// Groups not listed use JSON_DECODING_DEFAULT. Groups public clients call
+	// should stay lenient, so older clients sending removed fields keep
+	// working.
+	JSONDecoding        map[string]string `env:"JSON_DECODING"`
+	JSONDecodingDefault string            `env:"JSON_DECODING_DEFAULT" envDefault:"lenient"`
+
+	// Cross-origin requests from browsers. Origins are given as
+	// scheme://host[:port], or "*" to allow any origin, and default to
+	// CLIENT_ORIGIN. Preflight responses are reused for CORS_MAX_AGE.
+	// Credentials are allowed by default, as browser clients on another origin
+	// need them to send the CSRF cookie. They cannot be allowed along with any
+	// origin, so CORS_ALLOW_CREDENTIALS must be disabled to use "*", which
+	// leaves such clients only able to make requests with a bearer token.
+	CORSAllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods   []string      `env:"CORS_ALLOWED_METHODS" envDefault:"GET,POST,PUT,PATCH,DELETE"`
+	CORSAllowedHeaders   []string      `env:"CORS_ALLOWED_HEADERS" envDefault:"Authorization,Content-Type,X-CSRF-Token,X-Request-ID"`
+	CORSExposedHeaders   []string      `env:"CORS_EXPOSED_HEADERS" envDefault:"Retry-After,X-Request-ID"`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"true"`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" envDefault:"10m"`
+
+	// Overload protection. Low priority requests are shed once the load
+	// reaches 75% of either limit and normal priority ones at 100%. Zero
+	// disables a limit.
+	LoadShedMaxInFlight    int           `env:"LOAD_SHED_MAX_IN_FLIGHT" envDefault:"0"`
+	LoadShedMaxLag         time.Duration `env:"LOAD_SHED_MAX_LAG" envDefault:"0s"`
+	LoadShedSampleInterval time.Duration `env:"LOAD_SHED_SAMPLE_INTERVAL" envDefault:"100ms"`
+
+	// EventsAnonymize truncates client addresses and drops user agents before
+	// analytics events are stored.
+	EventsAnonymize bool `env:"EVENTS_ANONYMIZE" envDefault:"true"`
+
+	// Targeted fractions of good requests reported by the SLO endpoint.
+	SLOAvailabilityTarget float64 `env:"SLO_AVAILABILITY_TARGET" envDefault:"0.999"`
+	SLOLatencyTarget      float64 `env:"SLO_LATENCY_TARGET" envDefault:"0.99"`
+
+	// Outbound http client settings used for webhook and third-party calls.
+	HTTPClientTimeout          time.Duration `env:"HTTP_CLIENT_TIMEOUT" envDefault:"10s"`
+	HTTPClientMaxConnsPerHost  int           `env:"HTTP_CLIENT_MAX_CONNS_PER_HOST" envDefault:"10"`
+	HTTPClientFailureThreshold int           `env:"HTTP_CLIENT_FAILURE_THRESHOLD" envDefault:"5"`
+	HTTPClientCooldown         time.Duration `env:"HTTP_CLIENT_COOLDOWN" envDefault:"30s"`
+
+	// Dependency health sampling, with the most recent results kept in memory.
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"15s"`
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	HealthHistorySize   int           `env:"HEALTH_HISTORY_SIZE" envDefault:"240"`
+
+	// How long /healthz responses are reused, so frequent probes do not each
+	// hit the database.
+	HealthzCacheTTL time.Duration `env:"HEALTHZ_CACHE_TTL" envDefault:"500ms"`
+
+	// How long active announcement responses are reused. Every page load asks
+	// for them, so a short cache takes most of that load off the database.
+	AnnouncementsCacheTTL time.Duration `env:"ANNOUNCEMENTS_CACHE_TTL" envDefault:"500ms"`
+
+	// Addresses or CIDR ranges of the proxies in front of the server, whose
+	// forwarding headers are trusted to carry the client address, e.g.
+	// "10.0.0.0/8,127.0.0.1".
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+
+	// Type of event the Lambda entrypoint receives, "apigateway" for API
+	// Gateway REST APIs, "apigatewayv2" for HTTP APIs, or "alb".
+	LambdaEventSource string `env:"LAMBDA_EVENT_SOURCE" envDefault:"apigateway"`
+
+	// Fault injection for resilience testing. Never enable in production.
+	ChaosEnabled     bool          `env:"CHAOS_ENABLED" envDefault:"false"`
+	ChaosLatency     time.Duration `env:"CHAOS_LATENCY" envDefault:"0s"`
+	ChaosLatencyRate float64       `env:"CHAOS_LATENCY_RATE" envDefault:"0"`
+	ChaosErrorRate   float64       `env:"CHAOS_ERROR_RATE" envDefault:"0"`
 }
 
 // New loads configuration from environment variables and a .env file, and returns a
@@ -38,5 +315,155 @@ func New() (Config, error) {
 		return Config{}, fmt.Errorf("[in config.New] failed to parse config: %w", err)
 	}
 
+	// Connection settings are only required when talking to a database server.
+	// SQLite uses DATABASE_NAME as the file path, or ":memory:".
+	switch cfg.DBDriver {
+	case DriverPostgres, DriverMySQL:
+		if cfg.DBHost == "" || cfg.DBUserName == "" || cfg.DBUserPassword == "" || cfg.DBPort == "" {
+			return Config{}, fmt.Errorf(
+				"[in config.New] DATABASE_HOST, DATABASE_USER, DATABASE_PASSWORD and DATABASE_PORT are required for driver %q",
+				cfg.DBDriver,
+			)
+		}
+	case DriverSQLite:
+	default:
+		return Config{}, fmt.Errorf("[in config.New] unsupported DB_DRIVER %q", cfg.DBDriver)
+	}
+
+	for name, sink := range map[string]LogSink{
+		"LOG":          cfg.AppLog,
+		"ACCESS_LOG":   cfg.AccessLog,
+		"SECURITY_LOG": cfg.SecurityLog,
+	} {
+		switch sink.Output {
+		case LogOutputStdout, LogOutputSyslog:
+		case LogOutputFile:
+			if sink.FilePath == "" {
+				return Config{}, fmt.Errorf("[in config.New] %s_FILE_PATH is required for file output", name)
+			}
+		default:
+			return Config{}, fmt.Errorf("[in config.New] unsupported %s_OUTPUT %q", name, sink.Output)
+		}
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return Config{}, fmt.Errorf("[in config.New] TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.HTTP3Enabled && cfg.TLSCertFile == "" {
+		return Config{}, fmt.Errorf("[in config.New] HTTP3_ENABLED requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	if cfg.JWTSecret == "" && len(cfg.JWTKeyFiles) == 0 {
+		return Config{}, fmt.Errorf("[in config.New] JWT_SECRET or JWT_KEY_FILES is required")
+	}
+	for id := range cfg.JWTKeyActivation {
+		if _, ok := cfg.JWTKeyFiles[id]; !ok && !(id == DefaultJWTKeyID && cfg.JWTSecret != "") {
+			return Config{}, fmt.Errorf("[in config.New] JWT_KEY_ACTIVATION names unknown key %q", id)
+		}
+	}
+
+	switch cfg.SecurityAlertSeverity {
+	case "info", "warning", "critical":
+	default:
+		return Config{}, fmt.Errorf("[in config.New] unsupported SECURITY_ALERT_SEVERITY %q", cfg.SecurityAlertSeverity)
+	}
+	if cfg.SecurityAlertWebhookURL != "" {
+		u, err := url.Parse(cfg.SecurityAlertWebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return Config{}, fmt.Errorf("[in config.New] SECURITY_ALERT_WEBHOOK_URL must be an absolute http(s) URL")
+		}
+	}
+
+	if cfg.EmbeddingURL, err = checkProvider("EMBEDDING", cfg.EmbeddingProvider, cfg.EmbeddingURL, cfg.EmbeddingModel); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmbeddingProvider != "" && (cfg.EmbeddingBatchSize < 1 || cfg.EmbeddingInterval <= 0) {
+		return Config{}, fmt.Errorf("[in config.New] EMBEDDING_BATCH_SIZE and EMBEDDING_INTERVAL must be positive")
+	}
+	if cfg.LLMURL, err = checkProvider("LLM", cfg.LLMProvider, cfg.LLMURL, cfg.LLMModel); err != nil {
+		return Config{}, err
+	}
+
+	for group, mode := range cfg.JSONDecoding {
+		if mode != JSONDecodingStrict && mode != JSONDecodingLenient {
+			return Config{}, fmt.Errorf("[in config.New] unsupported JSON_DECODING %q for group %q", mode, group)
+		}
+	}
+	if cfg.JSONDecodingDefault != JSONDecodingStrict && cfg.JSONDecodingDefault != JSONDecodingLenient {
+		return Config{}, fmt.Errorf("[in config.New] unsupported JSON_DECODING_DEFAULT %q", cfg.JSONDecodingDefault)
+	}
+
+	if cfg.LintBudget <= 0 || cfg.LintMaxParagraphWords < 1 {
+		return Config{}, fmt.Errorf("[in config.New] LINT_BUDGET and LINT_MAX_PARAGRAPH_WORDS must be positive")
+	}
+
+	switch cfg.CSRFCookieSameSite {
+	case "strict", "lax":
+	case "none":
+		if !cfg.CSRFCookieSecure {
+			return Config{}, fmt.Errorf("[in config.New] CSRF_COOKIE_SAME_SITE none requires CSRF_COOKIE_SECURE")
+		}
+	default:
+		return Config{}, fmt.Errorf("[in config.New] unsupported CSRF_COOKIE_SAME_SITE %q", cfg.CSRFCookieSameSite)
+	}
+
+	if len(cfg.CORSAllowedOrigins) == 0 {
+		cfg.CORSAllowedOrigins = []string{cfg.ClientOrigin}
+	}
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			if cfg.CORSAllowCredentials {
+				return Config{}, fmt.Errorf("[in config.New] CORS_ALLOW_CREDENTIALS must be disabled when any origin is allowed")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return Config{}, fmt.Errorf("[in config.New] CORS origin %q must be \"*\" or scheme://host[:port]", origin)
+		}
+	}
+	if cfg.CORSMaxAge < 0 {
+		return Config{}, fmt.Errorf("[in config.New] CORS_MAX_AGE must not be negative")
+	}
+
+	switch cfg.AccessLogFormat {
+	case "json", "clf":
+	default:
+		return Config{}, fmt.Errorf("[in config.New] unsupported ACCESS_LOG_FORMAT %q", cfg.AccessLogFormat)
+	}
+
+	switch cfg.LambdaEventSource {
+	case LambdaEventAPIGateway, LambdaEventAPIGatewayV2, LambdaEventALB:
+	default:
+		return Config{}, fmt.Errorf("[in config.New] unsupported LAMBDA_EVENT_SOURCE %q", cfg.LambdaEventSource)
+	}
+
 	return cfg, nil
 }
+
+// checkProvider checks the settings of the optional model provider read with
+// the provided environment variable prefix, returning its URL with the
+// provider's default address filled in.
+func checkProvider(prefix string, provider string, rawURL string, model string) (string, error) {
+	switch provider {
+	case "":
+		return rawURL, nil
+	case ProviderOpenAI, ProviderOllama:
+	default:
+		return "", fmt.Errorf("[in config.checkProvider] unsupported %s_PROVIDER %q", prefix, provider)
+	}
+
+	if rawURL == "" {
+		rawURL = providerURLs[provider]
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("[in config.checkProvider] %s_URL must be an absolute http(s) URL", prefix)
+	}
+
+	if model == "" {
+		return "", fmt.Errorf("[in config.checkProvider] %s_MODEL is required for %s_PROVIDER %q", prefix, prefix, provider)
+	}
+
+	return rawURL, nil
+}
@@ -0,0 +1,129 @@
+// Package crypto encrypts sensitive column values before they are stored, and
+// derives the blind indexes used to look them up.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks encrypted values, which are stored as "enc:<key id>:<data>" with
+// the data being the base64 encoded nonce followed by the sealed value.
+const prefix = "enc:"
+
+// ErrUnknownKey is returned when a value was encrypted with a key that is not
+// in the keyring.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Keyring encrypts values with AES-256-GCM using its current key, and decrypts
+// values encrypted with any of its keys. Keys are rotated by adding a new key,
+// making it current, and re-encrypting stored values before the old key is
+// removed.
+type Keyring struct {
+	current  string
+	aeads    map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring creates a new Keyring from the provided 32 byte keys by id and
+// returns a pointer to it. New values are encrypted with the key named by
+// current. indexKey is used to derive blind indexes, and must never change
+// while indexes derived from it are stored.
+func NewKeyring(keys map[string][]byte, current string, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("[in crypto.NewKeyring] current key %q is not in the keyring", current)
+	}
+	if len(indexKey) < 32 {
+		return nil, fmt.Errorf("[in crypto.NewKeyring] index key must be at least 32 bytes")
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("[in crypto.NewKeyring] invalid key id %q", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("[in crypto.NewKeyring] key %q must be 32 bytes", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("[in crypto.NewKeyring] key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("[in crypto.NewKeyring] key %q: %w", id, err)
+		}
+		aeads[id] = aead
+	}
+
+	return &Keyring{current: current, aeads: aeads, indexKey: indexKey}, nil
+}
+
+// Encrypt encrypts the provided value with the current key.
+func (k *Keyring) Encrypt(value string) (string, error) {
+	aead := k.aeads[k.current]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("[in crypto.Keyring.Encrypt] failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(k.current))
+	return prefix + k.current + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt. Values that are not encrypted,
+// such as those stored before encryption was enabled, are returned as is.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, data, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", fmt.Errorf("[in crypto.Keyring.Decrypt] malformed value")
+	}
+
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("[in crypto.Keyring.Decrypt] key %q: %w", id, ErrUnknownKey)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("[in crypto.Keyring.Decrypt] malformed value")
+	}
+
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("[in crypto.Keyring.Decrypt] failed to decrypt: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// Current reports whether the provided stored value is encrypted with the
+// current key. Values that are not, including plaintext, should be
+// re-encrypted.
+func (k *Keyring) Current(value string) bool {
+	return strings.HasPrefix(value, prefix+k.current+":")
+}
+
+// Index returns the blind index of the provided value, a keyed hash that
+// allows stored values to be found by equality without decrypting them.
+func (k *Keyring) Index(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
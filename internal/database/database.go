@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/jha-captech/blog/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSetup holds the schema and seed data applied to a fresh SQLite
+// database.
+//
+//go:embed sqlite_setup.sql
+var sqliteSetup string
+
+// Connect opens a database connection for the driver selected in config,
+// verifies it with a ping, and returns it or an error.
+func Connect(ctx context.Context, logger *slog.Logger, cfg config.Config) (*DB, error) {
+	open := func(ctx context.Context) (*sql.DB, error) {
+		return openPool(ctx, logger, cfg)
+	}
+
+	pool, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{
+		Dialect: Dialect(cfg.DBDriver),
+		logger:  logger,
+		open:    open,
+	}
+	db.pool.Store(pool)
+
+	// Rebuilding an in-memory SQLite database would throw away all of its data,
+	// and there is no server address to re-resolve anyway.
+	if cfg.DBDriver != config.DriverSQLite {
+		db.rebuildThreshold = cfg.DBRebuildAfterErrors
+	}
+
+	return db, nil
+}
+
+// openPool opens and configures a new connection pool, verifying it with a
+// ping.
+func openPool(ctx context.Context, logger *slog.Logger, cfg config.Config) (*sql.DB, error) {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	switch cfg.DBDriver {
+	case config.DriverPostgres:
+		db, err = sql.Open("pgx", fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+			cfg.DBHost,
+			cfg.DBUserName,
+			cfg.DBUserPassword,
+			cfg.DBName,
+			cfg.DBPort,
+		))
+	case config.DriverMySQL:
+		mysqlCfg := mysql.NewConfig()
+		mysqlCfg.User = cfg.DBUserName
+		mysqlCfg.Passwd = cfg.DBUserPassword
+		mysqlCfg.Net = "tcp"
+		mysqlCfg.Addr = net.JoinHostPort(cfg.DBHost, cfg.DBPort)
+		mysqlCfg.DBName = cfg.DBName
+		mysqlCfg.ParseTime = true
+		db, err = sql.Open("mysql", mysqlCfg.FormatDSN())
+	case config.DriverSQLite:
+		db, err = sql.Open("sqlite", cfg.DBName)
+	default:
+		return nil, fmt.Errorf("[in database.openPool] unsupported driver %q", cfg.DBDriver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[in database.openPool] failed to open database: %w", err)
+	}
+
+	// Recycle connections periodically so that a pool never holds on to
+	// connections to a server address that is no longer valid, e.g. after a
+	// failover changes the IP behind the database's DNS name.
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+
+	// Ping the database to verify connection
+	logger.DebugContext(ctx, "Pinging database", slog.String("driver", cfg.DBDriver))
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("[in database.openPool] failed to ping database: %w", err)
+	}
+
+	if cfg.DBDriver == config.DriverSQLite {
+		// SQLite only allows a single writer, and every connection to an
+		// in-memory database gets its own empty database, so keep the pool to
+		// a single connection that is never recycled.
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+
+		// Foreign keys are only enforced when enabled on the connection.
+		if _, err = db.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("[in database.openPool] failed to enable sqlite foreign keys: %w", err)
+		}
+
+		if err = setupSQLite(ctx, logger, db); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("[in database.openPool] failed to set up sqlite: %w", err)
+		}
+	}
+
+	return db, nil
+}
+
+// setupSQLite creates the schema and seeds a SQLite database the first time it
+// is used. Databases that already have a users table are left untouched.
+func setupSQLite(ctx context.Context, logger *slog.Logger, db *sql.DB) error {
+	var count int
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'users'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("[in database.setupSQLite] failed to check schema: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	logger.InfoContext(ctx, "Creating sqlite schema")
+	if _, err = db.ExecContext(ctx, sqliteSetup); err != nil {
+		return fmt.Errorf("[in database.setupSQLite] failed to create schema: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/jha-captech/blog/internal/chaos"
+	"github.com/jha-captech/blog/internal/metrics"
+)
+
+// DB wraps a sql.DB, translating queries written in Postgres syntax into the
+// dialect of the underlying driver. When configured, it also rebuilds its
+// connection pool after repeated connection errors.
+type DB struct {
+	Dialect Dialect
+
+	logger *slog.Logger
+	open   func(ctx context.Context) (*sql.DB, error)
+	pool   atomic.Pointer[sql.DB]
+
+	// rebuildThreshold is the number of consecutive connection errors after
+	// which the pool is rebuilt. Zero disables rebuilding.
+	rebuildThreshold int
+	connErrors       atomic.Int64
+	rebuilding       atomic.Bool
+
+	// chaos injects faults into queries when chaos testing is enabled.
+	chaos *chaos.Injector
+
+	// Query metrics, recorded once SetMetrics is called.
+	queries        *metrics.Counter
+	queryDurations *metrics.Histogram
+}
+
+// SetChaos enables fault injection on every query using the provided injector.
+// Rows returned by QueryRowContext can only be delayed, not failed.
+func (db *DB) SetChaos(injector *chaos.Injector) {
+	db.chaos = injector
+}
+
+// SetMetrics records the number, duration and outcome of every query, by
+// statement type, in the provided registry.
+func (db *DB) SetMetrics(registry *metrics.Registry) {
+	db.queries = registry.Counter(
+		"db_queries_total",
+		"Number of database queries, by statement type and outcome.",
+		"operation", "outcome",
+	)
+	db.queryDurations = registry.Histogram(
+		"db_query_duration_seconds",
+		"Duration of database queries, by statement type.",
+		metrics.DefaultBuckets,
+		"operation",
+	)
+}
+
+// Pool returns the current underlying connection pool.
+func (db *DB) Pool() *sql.DB {
+	return db.pool.Load()
+}
+
+// PingContext verifies the connection to the database is still alive.
+func (db *DB) PingContext(ctx context.Context) error {
+	err := db.Pool().PingContext(ctx)
+	db.observe(err)
+	return err
+}
+
+// Close closes the underlying connection pool.
+func (db *DB) Close() error {
+	return db.Pool().Close()
+}
+
+// ExecContext executes a query without returning any rows.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := db.chaos.Inject(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	query, args = db.Dialect.Rebind(query, args)
+	result, err := db.Pool().ExecContext(ctx, query, args...)
+	db.observe(err)
+	db.record(query, start, err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := db.chaos.Inject(ctx); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	query, args = db.Dialect.Rebind(query, args)
+	rows, err := db.Pool().QueryContext(ctx, query, args...)
+	db.observe(err)
+	db.record(query, start, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	db.chaos.Delay(ctx)
+
+	start := time.Now()
+	query, args = db.Dialect.Rebind(query, args)
+	row := db.Pool().QueryRowContext(ctx, query, args...)
+	db.observe(row.Err())
+	db.record(query, start, row.Err())
+	return row
+}
+
+// InsertReturningID executes an INSERT statement and returns the id of the new
+// row. The statement must not contain a RETURNING clause; one is added for
+// dialects that support it, otherwise the driver's last insert id is used.
+func (db *DB) InsertReturningID(ctx context.Context, query string, args ...any) (int64, error) {
+	id, err := insertReturningID(ctx, db.Dialect, db, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("[in database.DB.InsertReturningID] %w", err)
+	}
+	return id, nil
+}
+
+// insertReturningID executes an INSERT statement with q and returns the id of
+// the new row, as described by DB.InsertReturningID.
+func insertReturningID(ctx context.Context, dialect Dialect, q Querier, query string, args []any) (int64, error) {
+	if dialect.SupportsReturning() {
+		var id int64
+		if err := q.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id); err != nil {
+			return 0, fmt.Errorf("failed to insert: %w", err)
+		}
+		return id, nil
+	}
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read insert id: %w", err)
+	}
+
+	return id, nil
+}
+
+// observe tracks consecutive connection errors, starting a pool rebuild once
+// the threshold is reached.
+func (db *DB) observe(err error) {
+	if db.rebuildThreshold <= 0 {
+		return
+	}
+
+	if !isConnError(err) {
+		db.connErrors.Store(0)
+		return
+	}
+
+	count := db.connErrors.Add(1)
+	db.logger.Warn("Database connection error", slog.Int64("consecutive", count), slog.String("error", err.Error()))
+
+	if count >= int64(db.rebuildThreshold) && db.rebuilding.CompareAndSwap(false, true) {
+		go db.rebuild()
+	}
+}
+
+// record adds a query to the query metrics. Queries returning rows are timed
+// until their first row is ready, not until the rows are read.
+func (db *DB) record(query string, start time.Time, err error) {
+	operation := "other"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		switch keyword := strings.ToLower(fields[0]); keyword {
+		case "select", "insert", "update", "delete":
+			operation = keyword
+		}
+	}
+
+	outcome := "success"
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		outcome = "error"
+	}
+
+	db.queries.Inc(operation, outcome)
+	db.queryDurations.Observe(time.Since(start).Seconds(), operation)
+}
+
+// rebuild opens a new connection pool, which re-resolves the database host,
+// and swaps it in for the current one.
+func (db *DB) rebuild() {
+	defer db.rebuilding.Store(false)
+
+	db.logger.Warn("Rebuilding database connection pool")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pool, err := db.open(ctx)
+	if err != nil {
+		db.logger.Error("Failed to rebuild database connection pool", slog.String("error", err.Error()))
+		return
+	}
+
+	// Close waits for queries already running on the old pool to finish.
+	old := db.pool.Swap(pool)
+	db.connErrors.Store(0)
+	if err = old.Close(); err != nil {
+		db.logger.Error("Failed to close old database connection pool", slog.String("error", err.Error()))
+	}
+
+	db.logger.Info("Rebuilt database connection pool")
+}
+
+// isConnError reports whether err was caused by a broken or unreachable
+// database connection, as opposed to an error in the query itself.
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
+
+// IsUniqueViolation reports whether err was caused by a statement breaking a
+// unique constraint, such as inserting a row whose key is already taken.
+func IsUniqueViolation(err error) bool {
+	var (
+		pgErr     *pgconn.PgError
+		mysqlErr  *mysql.MySQLError
+		sqliteErr *sqlite.Error
+	)
+
+	switch {
+	case errors.As(err, &pgErr):
+		return pgErr.Code == "23505"
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == 1062
+	case errors.As(err, &sqliteErr):
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	default:
+		return false
+	}
+}
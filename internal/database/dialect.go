@@ -0,0 +1,136 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/config"
+)
+
+// Dialect describes the differences in SQL syntax between the supported
+// drivers. Queries in the services package are written using Postgres syntax
+// ($N placeholders) and translated by the dialect when needed.
+type Dialect string
+
+// Supported dialects.
+const (
+	DialectPostgres Dialect = config.DriverPostgres
+	DialectSQLite   Dialect = config.DriverSQLite
+	DialectMySQL    Dialect = config.DriverMySQL
+)
+
+// SupportsReturning reports whether the dialect supports a RETURNING clause on
+// INSERT and UPDATE statements.
+func (d Dialect) SupportsReturning() bool {
+	return d != DialectMySQL
+}
+
+// Rebind converts a query written with $N placeholders into the placeholder
+// style of the dialect. MySQL only supports positional ? placeholders, so the
+// args are reordered (and duplicated when a placeholder is reused) to match the
+// order the placeholders appear in the query.
+func (d Dialect) Rebind(query string, args []any) (string, []any) {
+	if d != DialectMySQL {
+		return query, args
+	}
+
+	var (
+		sb    strings.Builder
+		bound = make([]any, 0, len(args))
+	)
+
+	for i := 0; i < len(query); i++ {
+		if query[i] != '$' {
+			sb.WriteByte(query[i])
+			continue
+		}
+
+		j := i + 1
+		for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+			j++
+		}
+
+		n, err := strconv.Atoi(query[i+1 : j])
+		if err != nil || n < 1 || n > len(args) {
+			sb.WriteByte(query[i])
+			continue
+		}
+
+		sb.WriteByte('?')
+		bound = append(bound, args[n-1])
+		i = j - 1
+	}
+
+	return sb.String(), bound
+}
+
+// Upsert returns the clause that turns an INSERT into an upsert, updating
+// updateColumns when a row with the same conflictColumns already exists.
+func (d Dialect) Upsert(conflictColumns []string, updateColumns []string) string {
+	sets := make([]string, len(updateColumns))
+
+	if d == DialectMySQL {
+		for i, column := range updateColumns {
+			sets[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+
+	for i, column := range updateColumns {
+		sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+	}
+	return fmt.Sprintf(
+		"ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(conflictColumns, ", "),
+		strings.Join(sets, ", "),
+	)
+}
+
+// sqliteTimeFormat is the format of SQLite's CURRENT_TIMESTAMP.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// TimeArg converts t into a query argument that compares correctly with
+// timestamp columns. SQLite stores timestamps as text, so they are only
+// ordered correctly against values in the same format as CURRENT_TIMESTAMP,
+// which has second precision.
+func (d Dialect) TimeArg(t time.Time) any {
+	if d == DialectSQLite {
+		return t.UTC().Format(sqliteTimeFormat)
+	}
+	return t
+}
+
+// Time scans a timestamp computed by a query, such as MAX(created_at). SQLite
+// returns those as text, unlike values read straight from timestamp columns.
+type Time struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner.
+func (t *Time) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	default:
+		return fmt.Errorf("[in database.Time.Scan] unsupported type %T", src)
+	}
+}
+
+// parse parses text in the format of TimeArg or RFC 3339.
+func (t *Time) parse(s string) error {
+	parsed, err := time.ParseInLocation(sqliteTimeFormat, s, time.UTC)
+	if err != nil {
+		if parsed, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return fmt.Errorf("[in database.Time.Scan] failed to parse %q: %w", s, err)
+		}
+	}
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Querier runs queries written in Postgres syntax, either on the connection
+// pool of a DB or within a Tx, so the same code can run in and out of a
+// transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	InsertReturningID(ctx context.Context, query string, args ...any) (int64, error)
+}
+
+// Tx is a transaction begun by DB.InTx.
+type Tx struct {
+	db *DB
+	tx *sql.Tx
+}
+
+// InTx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back otherwise. Queries in fn must go through the provided Tx: SQLite
+// pools hold a single connection, which the transaction takes up.
+func (db *DB) InTx(ctx context.Context, fn func(tx *Tx) error) error {
+	if err := db.chaos.Inject(ctx); err != nil {
+		return err
+	}
+
+	sqlTx, err := db.Pool().BeginTx(ctx, nil)
+	db.observe(err)
+	if err != nil {
+		return fmt.Errorf("[in database.DB.InTx] failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&Tx{db: db, tx: sqlTx}); err != nil {
+		if rollbackErr := sqlTx.Rollback(); rollbackErr != nil {
+			db.logger.ErrorContext(ctx, "Failed to roll back transaction", slog.String("error", rollbackErr.Error()))
+		}
+		return err
+	}
+
+	err = sqlTx.Commit()
+	db.observe(err)
+	if err != nil {
+		return fmt.Errorf("[in database.DB.InTx] failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ExecContext executes a query without returning any rows.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	query, args = tx.db.Dialect.Rebind(query, args)
+	result, err := tx.tx.ExecContext(ctx, query, args...)
+	tx.db.observe(err)
+	tx.db.record(query, start, err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	query, args = tx.db.Dialect.Rebind(query, args)
+	rows, err := tx.tx.QueryContext(ctx, query, args...)
+	tx.db.observe(err)
+	tx.db.record(query, start, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	query, args = tx.db.Dialect.Rebind(query, args)
+	row := tx.tx.QueryRowContext(ctx, query, args...)
+	tx.db.observe(row.Err())
+	tx.db.record(query, start, row.Err())
+	return row
+}
+
+// InsertReturningID executes an INSERT statement and returns the id of the new
+// row, like DB.InsertReturningID.
+func (tx *Tx) InsertReturningID(ctx context.Context, query string, args ...any) (int64, error) {
+	id, err := insertReturningID(ctx, tx.db.Dialect, tx, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("[in database.Tx.InsertReturningID] %w", err)
+	}
+	return id, nil
+}
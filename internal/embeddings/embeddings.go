@@ -0,0 +1,15 @@
+// Package embeddings generates vector embeddings of text through a pluggable
+// provider, and keeps the embeddings of posts up to date in the background so
+// they can be searched by meaning.
+package embeddings
+
+import (
+	"context"
+)
+
+// Provider generates embeddings for texts, such as a hosted API or a local
+// model server.
+type Provider interface {
+	// Embed returns the embedding of each text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
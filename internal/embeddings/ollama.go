@@ -0,0 +1,54 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/httpclient"
+)
+
+// Ollama generates embeddings with a local Ollama model server, keeping post
+// content on infrastructure you run.
+type Ollama struct {
+	client *httpclient.Client
+	url    string
+	model  string
+}
+
+// NewOllama creates a new Ollama provider for the server at baseURL, e.g.
+// "http://localhost:11434", and returns a pointer to it.
+func NewOllama(client *httpclient.Client, baseURL string, model string) *Ollama {
+	return &Ollama{
+		client: client,
+		url:    strings.TrimSuffix(baseURL, "/") + "/api/embed",
+		model:  model,
+	}
+}
+
+// ollamaRequest represents the request body of the embed endpoint.
+type ollamaRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// ollamaResponse represents the response body of the embed endpoint, with the
+// embeddings in the order of the inputs.
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed returns the embedding of each text, in the same order.
+func (p *Ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp ollamaResponse
+	err := p.client.PostJSON(ctx, p.url, "", ollamaRequest{Model: p.model, Input: texts}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("[in embeddings.Ollama.Embed] %w", err)
+	}
+
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("[in embeddings.Ollama.Embed] provider returned %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+
+	return resp.Embeddings, nil
+}
@@ -0,0 +1,67 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/httpclient"
+)
+
+// OpenAI generates embeddings with the embeddings endpoint of the OpenAI API,
+// or of any server compatible with it, such as vLLM or LocalAI.
+type OpenAI struct {
+	client *httpclient.Client
+	url    string
+	model  string
+	apiKey string
+}
+
+// NewOpenAI creates a new OpenAI provider for the API at baseURL, e.g.
+// "https://api.openai.com/v1", and returns a pointer to it.
+func NewOpenAI(client *httpclient.Client, baseURL string, model string, apiKey string) *OpenAI {
+	return &OpenAI{
+		client: client,
+		url:    strings.TrimSuffix(baseURL, "/") + "/embeddings",
+		model:  model,
+		apiKey: apiKey,
+	}
+}
+
+// openAIRequest represents the request body of the embeddings endpoint.
+type openAIRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIResponse represents the response body of the embeddings endpoint.
+// Each embedding carries the index of its input, as they may be out of order.
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns the embedding of each text, in the same order.
+func (p *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp openAIResponse
+	err := p.client.PostJSON(ctx, p.url, p.apiKey, openAIRequest{Model: p.model, Input: texts}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("[in embeddings.OpenAI.Embed] %w", err)
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("[in embeddings.OpenAI.Embed] provider returned %d embeddings for %d texts", len(resp.Data), len(texts))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("[in embeddings.OpenAI.Embed] provider returned embedding for unknown input %d", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
@@ -0,0 +1,120 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/services"
+)
+
+// PostStore represents a type capable of finding the posts whose embedding is
+// missing or outdated and storing their new embeddings.
+type PostStore interface {
+	ListPostsNeedingEmbedding(ctx context.Context, limit int) ([]models.Post, error)
+	SetPostEmbedding(ctx context.Context, id uint64, embedding []float32) error
+}
+
+// Worker periodically generates embeddings for posts that were created or
+// updated since they were last embedded.
+type Worker struct {
+	logger    *slog.Logger
+	posts     PostStore
+	provider  Provider
+	batchSize int
+}
+
+// NewWorker creates a new Worker embedding up to batchSize posts per provider
+// call and returns a pointer to it.
+func NewWorker(logger *slog.Logger, posts PostStore, provider Provider, batchSize int) *Worker {
+	return &Worker{
+		logger:    logger,
+		posts:     posts,
+		provider:  provider,
+		batchSize: batchSize,
+	}
+}
+
+// Run embeds pending posts once per interval until ctx is done. Failures are
+// logged and retried on the next run.
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		embedded, err := w.EmbedPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to embed posts", slog.String("error", err.Error()))
+		}
+		if embedded > 0 {
+			w.logger.InfoContext(ctx, "Embedded posts", slog.Int("count", embedded))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EmbedPending embeds every post needing an embedding, a batch at a time. The
+// number of posts embedded is returned, along with the first error, after
+// which no more batches are attempted. Posts deleted in the meantime are
+// skipped.
+func (w *Worker) EmbedPending(ctx context.Context) (int, error) {
+	// Posts edited while being embedded are left for the next run, so a post
+	// that keeps changing can't hold up the others.
+	seen := make(map[uint]bool)
+
+	var embedded int
+	for {
+		posts, err := w.posts.ListPostsNeedingEmbedding(ctx, w.batchSize)
+		if err != nil {
+			return embedded, fmt.Errorf("[in embeddings.Worker.EmbedPending] %w", err)
+		}
+		full := len(posts) == w.batchSize
+
+		pending := posts[:0]
+		for _, post := range posts {
+			if !seen[post.ID] {
+				pending = append(pending, post)
+			}
+		}
+		posts = pending
+		if len(posts) == 0 {
+			return embedded, nil
+		}
+
+		texts := make([]string, len(posts))
+		for i, post := range posts {
+			texts[i] = post.Title + "\n\n" + post.Body
+		}
+
+		vectors, err := w.provider.Embed(ctx, texts)
+		if err != nil {
+			return embedded, fmt.Errorf("[in embeddings.Worker.EmbedPending] %w", err)
+		}
+
+		for i, post := range posts {
+			seen[post.ID] = true
+
+			err = w.posts.SetPostEmbedding(ctx, uint64(post.ID), vectors[i])
+			if errors.Is(err, services.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return embedded, fmt.Errorf("[in embeddings.Worker.EmbedPending] %w", err)
+			}
+			embedded++
+		}
+
+		// A short batch means every pending post has been seen.
+		if !full {
+			return embedded, nil
+		}
+	}
+}
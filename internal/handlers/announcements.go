@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// announcementsLister represents a type capable of listing every announcement.
+type announcementsLister interface {
+	ListAnnouncements(ctx context.Context) ([]models.Announcement, error)
+}
+
+// activeAnnouncementsLister represents a type capable of listing the
+// announcements shown to an audience at a point in time.
+type activeAnnouncementsLister interface {
+	ListActiveAnnouncements(ctx context.Context, at time.Time, audience string) ([]models.Announcement, error)
+}
+
+// announcementCreator represents a type capable of creating an announcement.
+type announcementCreator interface {
+	CreateAnnouncement(ctx context.Context, announcement models.Announcement) (models.Announcement, error)
+}
+
+// announcementUpdater represents a type capable of replacing an announcement.
+type announcementUpdater interface {
+	UpdateAnnouncement(ctx context.Context, id uint64, patch models.Announcement) (models.Announcement, error)
+}
+
+// announcementDeleter represents a type capable of deleting an announcement.
+type announcementDeleter interface {
+	DeleteAnnouncement(ctx context.Context, id uint64) error
+}
+
+// announcementRequest represents the request for creating or replacing an
+// announcement.
+type announcementRequest struct {
+	Message  string    `json:"message"`
+	Audience string    `json:"audience"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Valid checks the message length, the audience, and that the announcement
+// ends after it starts.
+func (req announcementRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("message", req.Message, validation.Required(), validation.MaxLength(500))
+	problems.Field("audience", req.Audience, validation.OneOf(announcementAudiences...))
+	problems.Check(!req.StartsAt.IsZero(), "starts_at", "must be set")
+	problems.Check(req.EndsAt.After(req.StartsAt), "ends_at", "must be after starts_at")
+
+	return problems
+}
+
+// announcementResponse represents an announcement in responses.
+type announcementResponse struct {
+	ID        uint      `json:"id"`
+	Message   string    `json:"message"`
+	Audience  string    `json:"audience"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newAnnouncementResponse converts a models.Announcement domain model into a
+// response model.
+func newAnnouncementResponse(announcement models.Announcement) announcementResponse {
+	return announcementResponse{
+		ID:        announcement.ID,
+		Message:   announcement.Message,
+		Audience:  announcement.Audience,
+		StartsAt:  announcement.StartsAt,
+		EndsAt:    announcement.EndsAt,
+		CreatedAt: announcement.CreatedAt,
+		UpdatedAt: announcement.UpdatedAt,
+	}
+}
+
+// newAnnouncementsResponse converts a list of announcements into response
+// models.
+func newAnnouncementsResponse(announcements []models.Announcement) []announcementResponse {
+	response := make([]announcementResponse, len(announcements))
+	for i, announcement := range announcements {
+		response[i] = newAnnouncementResponse(announcement)
+	}
+	return response
+}
+
+// announcementAudiences are the audiences an announcement can target.
+var announcementAudiences = []string{models.AudienceAll, models.AudienceAnonymous, models.AudienceAuthenticated}
+
+// HandleActiveAnnouncements handles the request for the announcements that
+// should currently be shown as banners.
+//
+//	@Summary		Active Announcements
+//	@Description	List the announcements currently shown to an audience
+//	@Tags			announcements
+//	@Produce		json
+//	@Param			audience	query		string	false	"anonymous or authenticated, defaults to anonymous"
+//	@Success		200			{array}		announcementResponse
+//	@Failure		400			{object}	problem.Details
+//	@Failure		500			{object}	problem.Details
+//	@Router			/announcements/active  [GET]
+func HandleActiveAnnouncements(logger *slog.Logger, activeAnnouncementsLister activeAnnouncementsLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		audience := params.Query("audience", validation.OneOf(announcementAudiences...))
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+		if audience == "" {
+			audience = models.AudienceAnonymous
+		}
+
+		announcements, err := activeAnnouncementsLister.ListActiveAnnouncements(ctx, time.Now(), audience)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to list active announcements",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, newAnnouncementsResponse(announcements))
+	})
+}
+
+// HandleListAnnouncements handles the list announcements request.
+//
+//	@Summary		List Announcements
+//	@Description	List every announcement, including past and scheduled ones
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{array}		announcementResponse
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/announcements  [GET]
+func HandleListAnnouncements(logger *slog.Logger, announcementsLister announcementsLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		announcements, err := announcementsLister.ListAnnouncements(r.Context())
+		if err != nil {
+			logger.ErrorContext(
+				r.Context(),
+				"failed to list announcements",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(r.Context(), logger, w, http.StatusOK, newAnnouncementsResponse(announcements))
+	})
+}
+
+// HandleCreateAnnouncement handles the create announcement request.
+//
+//	@Summary		Create Announcement
+//	@Description	Create a new announcement
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			announcement	body		announcementRequest	true	"Announcement"
+//	@Success		201				{object}	announcementResponse
+//	@Failure		400				{object}	problem.Details
+//	@Failure		401				{object}	problem.Details
+//	@Failure		403				{object}	problem.Details
+//	@Failure		500				{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/announcements  [POST]
+func HandleCreateAnnouncement(logger *slog.Logger, announcementCreator announcementCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		request, ok := decodeAnnouncement(w, r, logger)
+		if !ok {
+			return
+		}
+
+		announcement, err := announcementCreator.CreateAnnouncement(ctx, models.Announcement{
+			Message:  request.Message,
+			Audience: request.Audience,
+			StartsAt: request.StartsAt,
+			EndsAt:   request.EndsAt,
+		})
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to create announcement",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.Header().Set("Location", "/api/admin/announcements/"+strconv.FormatUint(uint64(announcement.ID), 10))
+		responseJSON(ctx, logger, w, http.StatusCreated, newAnnouncementResponse(announcement))
+	})
+}
+
+// HandleUpdateAnnouncement handles the update announcement request.
+//
+//	@Summary		Update Announcement
+//	@Description	Replace an announcement by ID
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string				true	"Announcement ID"
+//	@Param			announcement	body		announcementRequest	true	"Announcement"
+//	@Success		200				{object}	announcementResponse
+//	@Failure		400				{object}	problem.Details
+//	@Failure		401				{object}	problem.Details
+//	@Failure		403				{object}	problem.Details
+//	@Failure		404				{object}	problem.Details
+//	@Failure		500				{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/announcements/{id}  [PUT]
+func HandleUpdateAnnouncement(logger *slog.Logger, announcementUpdater announcementUpdater) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		request, ok := decodeAnnouncement(w, r, logger)
+		if !ok {
+			return
+		}
+
+		announcement, err := announcementUpdater.UpdateAnnouncement(ctx, id, models.Announcement{
+			Message:  request.Message,
+			Audience: request.Audience,
+			StartsAt: request.StartsAt,
+			EndsAt:   request.EndsAt,
+		})
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to update announcement")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, newAnnouncementResponse(announcement))
+	})
+}
+
+// HandleDeleteAnnouncement handles the delete announcement request.
+//
+//	@Summary		Delete Announcement
+//	@Description	Delete an announcement by ID
+//	@Tags			admin
+//	@Param			id	path	string	true	"Announcement ID"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/announcements/{id}  [DELETE]
+func HandleDeleteAnnouncement(logger *slog.Logger, announcementDeleter announcementDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		if err := announcementDeleter.DeleteAnnouncement(ctx, id); err != nil {
+			responseError(ctx, logger, w, r, err, "failed to delete announcement")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// decodeAnnouncement decodes and validates an announcement request, writing a
+// 400 response and returning false if it is invalid.
+func decodeAnnouncement(w http.ResponseWriter, r *http.Request, logger *slog.Logger) (announcementRequest, bool) {
+	request, problems, err := decodeValid[announcementRequest](r)
+	if err != nil {
+		logger.WarnContext(
+			r.Context(),
+			"invalid announcement request",
+			slog.String("error", err.Error()),
+		)
+
+		if problems == nil {
+			problems = validation.Problems{"body": "must be a valid JSON announcement"}
+		}
+		responseProblems(w, r, problems)
+		return announcementRequest{}, false
+	}
+
+	return request, true
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// canariesLister represents a type capable of listing every canary.
+type canariesLister interface {
+	ListCanaries(ctx context.Context) ([]models.Canary, error)
+}
+
+// canaryCreator represents a type capable of creating a canary and returning
+// it along with its password.
+type canaryCreator interface {
+	CreateCanary(ctx context.Context) (models.Canary, string, error)
+}
+
+// canaryRotator represents a type capable of replacing a canary with a new one
+// and returning it along with its password.
+type canaryRotator interface {
+	RotateCanary(ctx context.Context, userID uint64) (models.Canary, string, error)
+}
+
+// canaryResponse represents a canary in responses.
+type canaryResponse struct {
+	UserID    uint      `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newCanaryResponse converts a models.Canary domain model into a response
+// model.
+func newCanaryResponse(canary models.Canary) canaryResponse {
+	return canaryResponse{
+		UserID:    canary.UserID,
+		Name:      canary.Name,
+		Email:     canary.Email,
+		CreatedAt: canary.CreatedAt,
+	}
+}
+
+// canaryCredentialsResponse represents a new canary along with its password,
+// which is only ever returned once.
+type canaryCredentialsResponse struct {
+	canaryResponse
+	Password string `json:"password"`
+}
+
+// HandleListCanaries handles the list canaries request.
+//
+//	@Summary		List Canaries
+//	@Description	List the canary users whose use raises a security alert
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{array}		canaryResponse
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/canaries  [GET]
+func HandleListCanaries(logger *slog.Logger, canariesLister canariesLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		canaries, err := canariesLister.ListCanaries(ctx)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to list canaries")
+			return
+		}
+
+		response := make([]canaryResponse, len(canaries))
+		for i, canary := range canaries {
+			response[i] = newCanaryResponse(canary)
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// HandleCreateCanary handles the create canary request. The response holds the
+// canary's password, which cannot be retrieved later.
+//
+//	@Summary		Create Canary
+//	@Description	Create a canary user with generated credentials to plant
+//	@Tags			admin
+//	@Produce		json
+//	@Success		201	{object}	canaryCredentialsResponse
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/canaries  [POST]
+func HandleCreateCanary(logger *slog.Logger, canaryCreator canaryCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		canary, password, err := canaryCreator.CreateCanary(ctx)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to create canary")
+			return
+		}
+
+		responseCanaryCredentials(ctx, logger, w, canary, password)
+	})
+}
+
+// HandleRotateCanary handles the rotate canary request, replacing a canary
+// whose credentials may have been seen with a new one. The response holds the
+// new canary's password, which cannot be retrieved later.
+//
+//	@Summary		Rotate Canary
+//	@Description	Replace a canary user by user ID with one with new credentials
+//	@Tags			admin
+//	@Produce		json
+//	@Param			id	path		string	true	"Canary user ID"
+//	@Success		201	{object}	canaryCredentialsResponse
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/canaries/{id}/rotate  [POST]
+func HandleRotateCanary(logger *slog.Logger, canaryRotator canaryRotator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		canary, password, err := canaryRotator.RotateCanary(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to rotate canary")
+			return
+		}
+
+		responseCanaryCredentials(ctx, logger, w, canary, password)
+	})
+}
+
+// responseCanaryCredentials writes a new canary and its password as a 201
+// response. The response must not be cached, as it holds the password.
+func responseCanaryCredentials(
+	ctx context.Context,
+	logger *slog.Logger,
+	w http.ResponseWriter,
+	canary models.Canary,
+	password string,
+) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Location", "/api/users/"+strconv.FormatUint(uint64(canary.UserID), 10))
+	responseJSON(ctx, logger, w, http.StatusCreated, canaryCredentialsResponse{
+		canaryResponse: newCanaryResponse(canary),
+		Password:       password,
+	})
+}
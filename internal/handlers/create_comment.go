@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// maxCommentLength is the longest comment accepted, in characters.
+const maxCommentLength = 2000
+
+// commentCreator represents a type capable of creating a comment in storage
+// and returning it or an error.
+type commentCreator interface {
+	CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error)
+}
+
+// createCommentRequest represents the request for commenting on a post.
+// Comments are written by the logged in user.
+type createCommentRequest struct {
+	Message string `json:"message"`
+}
+
+// Valid checks that the message is neither empty nor too long.
+func (req createCommentRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("message", req.Message, validation.Required(), validation.MaxLength(maxCommentLength))
+
+	return problems
+}
+
+// commentResponse represents a comment in responses.
+type commentResponse struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id"`
+	AuthorID  uint      `json:"author_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newCommentResponse converts a models.Comment domain model into a response
+// model.
+func newCommentResponse(comment models.Comment) commentResponse {
+	return commentResponse{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		AuthorID:  comment.AuthorID,
+		Message:   comment.Message,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+// HandleCreateComment handles the request for commenting on a post.
+//
+//	@Summary		Create Comment
+//	@Description	Comment on a post
+//	@Tags			comment
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"Post ID"
+//	@Param			comment	body		createCommentRequest	true	"Comment"
+//	@Success		201		{object}	commentResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		404		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}/comments  [POST]
+func HandleCreateComment(logger *slog.Logger, commentCreator commentCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		postID := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[createCommentRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid create comment request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON comment"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Create the comment, written by the logged in user
+		authorID, _ := auth.UserIDFromContext(ctx)
+		comment, err := commentCreator.CreateComment(ctx, models.Comment{
+			PostID:   uint(postID),
+			AuthorID: authorID,
+			Message:  request.Message,
+		})
+		if err != nil {
+			// The user was deleted after the token was issued
+			if errors.Is(err, services.ErrAuthorNotFound) {
+				problem.Error(w, r, http.StatusUnauthorized, "The user the bearer token was issued to no longer exists")
+				return
+			}
+
+			responseError(ctx, logger, w, r, err, "failed to create comment")
+			return
+		}
+
+		w.Header().Set("Location", r.URL.Path+"/"+strconv.FormatUint(uint64(comment.ID), 10))
+		responseJSON(ctx, logger, w, http.StatusCreated, newCommentResponse(comment))
+	})
+}
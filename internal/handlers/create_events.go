@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/realip"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// maxEventsPerBatch is the largest number of events accepted in one request.
+const maxEventsPerBatch = 100
+
+// maxEventClockSkew is how far in the future an event's timestamp may be,
+// allowing for client clocks that are slightly ahead.
+const maxEventClockSkew = 5 * time.Minute
+
+// eventsCreator represents a type capable of storing analytics events and
+// returning an error if they could not be stored.
+type eventsCreator interface {
+	CreateEvents(ctx context.Context, events []models.Event) error
+}
+
+// eventRequest represents a single analytics event sent by a client.
+type eventRequest struct {
+	Type       string    `json:"type"`
+	Path       string    `json:"path"`
+	SessionID  string    `json:"session_id"`
+	Value      *float64  `json:"value,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// createEventsRequest represents the request for recording a batch of
+// analytics events.
+type createEventsRequest struct {
+	Events []eventRequest `json:"events"`
+}
+
+// Valid checks the batch size and every event in it.
+func (req createEventsRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Check(len(req.Events) > 0, "events", "must contain at least one event")
+	problems.Check(len(req.Events) <= maxEventsPerBatch, "events", fmt.Sprintf("must contain at most %d events", maxEventsPerBatch))
+
+	now := time.Now()
+	for i, event := range req.Events {
+		field := fmt.Sprintf("events[%d]", i)
+
+		problems.Field(field+".type", event.Type, validation.OneOf(models.EventPageView, models.EventScrollDepth))
+		if event.Type == models.EventScrollDepth {
+			problems.Check(
+				event.Value != nil && *event.Value >= 0 && *event.Value <= 100,
+				field+".value",
+				"must be a percentage between 0 and 100",
+			)
+		}
+
+		problems.Check(strings.HasPrefix(event.Path, "/"), field+".path", "must be an absolute path")
+		problems.Field(field+".session_id", event.SessionID, validation.Required(), validation.MaxBytes(64))
+		problems.Check(
+			!event.OccurredAt.IsZero() && !event.OccurredAt.After(now.Add(maxEventClockSkew)),
+			field+".occurred_at",
+			"must be set and not in the future",
+		)
+	}
+
+	return problems
+}
+
+// createEventsResponse represents the response for recording a batch of
+// analytics events.
+type createEventsResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// HandleCreateEvents handles the request for recording a batch of client-side
+// analytics events.
+//
+//	@Summary		Create Events
+//	@Description	Record a batch of client-side analytics events
+//	@Tags			events
+//	@Accept			json
+//	@Produce		json
+//	@Param			events	body		createEventsRequest	true	"Events"
+//	@Success		202		{object}	createEventsResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/events  [POST]
+func HandleCreateEvents(logger *slog.Logger, eventsCreator eventsCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Batches are small, larger bodies are rejected without reading them.
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+
+		request, problems, err := decodeValid[createEventsRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid events request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON events batch"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		clientIP := ""
+		if addr, ok := realip.FromContext(ctx); ok && addr.IsValid() {
+			clientIP = addr.String()
+		}
+
+		receivedAt := time.Now()
+		events := make([]models.Event, len(request.Events))
+		for i, event := range request.Events {
+			events[i] = models.Event{
+				Type:       event.Type,
+				Path:       event.Path,
+				SessionID:  event.SessionID,
+				Value:      event.Value,
+				ClientIP:   clientIP,
+				UserAgent:  r.UserAgent(),
+				OccurredAt: event.OccurredAt,
+				ReceivedAt: receivedAt,
+			}
+		}
+
+		if err := eventsCreator.CreateEvents(ctx, events); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to create events",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusAccepted, createEventsResponse{Accepted: len(events)})
+	})
+}
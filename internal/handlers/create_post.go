@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Longest title and body accepted for a post, in characters.
+const (
+	maxPostTitleLength = 200
+	maxPostBodyLength  = 100000
+)
+
+// postCreator represents a type capable of creating a post in storage and
+// returning it or an error.
+type postCreator interface {
+	CreatePost(ctx context.Context, post models.Post) (models.Post, error)
+}
+
+// createPostRequest represents the request for creating a post. Posts are
+// written by the logged in user.
+type createPostRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Valid checks that the title and body are neither empty nor too long.
+func (req createPostRequest) Valid(ctx context.Context) validation.Problems {
+	return validPostContent(req.Title, req.Body)
+}
+
+// validPostContent checks the title and body shared by the create and update
+// post requests.
+func validPostContent(title string, body string) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("title", strings.TrimSpace(title), validation.Required(), validation.MaxLength(maxPostTitleLength))
+	problems.Field("body", body, validation.Required(), validation.MaxLength(maxPostBodyLength))
+
+	return problems
+}
+
+// postResponse represents a post in responses.
+type postResponse struct {
+	ID        uint      `json:"id"`
+	AuthorID  uint      `json:"author_id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Summary   string    `json:"summary,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newPostResponse converts a models.Post domain model into a response model.
+func newPostResponse(post models.Post) postResponse {
+	return postResponse{
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		Title:     post.Title,
+		Body:      post.Body,
+		Summary:   post.Summary,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+	}
+}
+
+// HandleCreatePost handles the create post request.
+//
+//	@Summary		Create Post
+//	@Description	Create a new post
+//	@Tags			post
+//	@Accept			json
+//	@Produce		json
+//	@Param			post	body		createPostRequest	true	"Post"
+//	@Success		201		{object}	postResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts  [POST]
+func HandleCreatePost(logger *slog.Logger, postCreator postCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[createPostRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid create post request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON post"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Create the post, written by the logged in user
+		authorID, _ := auth.UserIDFromContext(ctx)
+		post, err := postCreator.CreatePost(ctx, models.Post{
+			AuthorID: authorID,
+			Title:    strings.TrimSpace(request.Title),
+			Body:     request.Body,
+		})
+		if err != nil {
+			// The user was deleted after the token was issued
+			if errors.Is(err, services.ErrAuthorNotFound) {
+				problem.Error(w, r, http.StatusUnauthorized, "The user the bearer token was issued to no longer exists")
+				return
+			}
+
+			logger.ErrorContext(
+				ctx,
+				"failed to create post",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.Header().Set("Location", "/api/posts/"+strconv.FormatUint(uint64(post.ID), 10))
+		responseJSON(ctx, logger, w, http.StatusCreated, newPostResponse(post))
+	})
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/password"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// minPasswordLength is the shortest password accepted for a user.
+const minPasswordLength = 8
+
+// userCreator represents a type capable of creating a user in storage and
+// returning it or an error.
+type userCreator interface {
+	CreateUser(ctx context.Context, user models.User, password string) (models.User, error)
+}
+
+// createUserRequest represents the request for creating a user.
+type createUserRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Valid checks that every field is set and the email address is well formed.
+func (req createUserRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("name", strings.TrimSpace(req.Name), validation.Required(), validation.MaxLength(100))
+	problems.Field("email", req.Email, validation.Required(), validation.Email())
+	problems.Field("password", req.Password, passwordRules...)
+
+	return problems
+}
+
+// passwordRules are the rules a new password must follow. Passwords are hashed
+// with bcrypt, which only uses the first password.MaxLength bytes.
+var passwordRules = []validation.Rule{
+	validation.MinLength(minPasswordLength),
+	validation.MaxBytes(password.MaxLength),
+}
+
+// userResponse represents a user in responses. The password hash is never sent
+// back.
+type userResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HandleCreateUser handles the create user request.
+//
+//	@Summary		Create User
+//	@Description	Create a new user
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			user	body		createUserRequest	true	"User"
+//	@Success		201		{object}	userResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		409		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/users  [POST]
+func HandleCreateUser(logger *slog.Logger, userCreator userCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[createUserRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid create user request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON user"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Create the user
+		user, err := userCreator.CreateUser(ctx, models.User{
+			Name:  strings.TrimSpace(request.Name),
+			Email: request.Email,
+		}, request.Password)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to create user")
+			return
+		}
+
+		// Convert our models.User domain model into a response model.
+		response := userResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+
+		w.Header().Set("Location", "/api/users/"+strconv.FormatUint(uint64(user.ID), 10))
+		responseJSON(ctx, logger, w, http.StatusCreated, response)
+	})
+}
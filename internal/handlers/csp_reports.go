@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// maxCSPReportsPerRequest is the largest number of reports stored from one
+// request. Browsers batch reports sent with the Reporting API.
+const maxCSPReportsPerRequest = 100
+
+// maxCSPReportFieldLength is the length report fields are truncated to, in
+// bytes. URIs can be arbitrarily long, and reports are not authenticated.
+const maxCSPReportFieldLength = 1024
+
+// cspReportsCreator represents a type capable of storing CSP violation
+// reports and returning an error if they could not be stored.
+type cspReportsCreator interface {
+	CreateCSPReports(ctx context.Context, reports []models.CSPReport) error
+}
+
+// cspReportsSummarizer represents a type capable of counting the CSP violation
+// reports received since a point in time.
+type cspReportsSummarizer interface {
+	SummarizeCSPReports(ctx context.Context, since time.Time, limit int) ([]models.CSPReportSummary, error)
+}
+
+// legacyCSPReport is a report sent to a report-uri directive, with the
+// application/csp-report content type.
+type legacyCSPReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		EffectiveDirective string `json:"effective-directive"`
+		ViolatedDirective  string `json:"violated-directive"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is a report sent to a report-to endpoint by the Reporting
+// API, in batches with the application/reports+json content type.
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+	} `json:"body"`
+}
+
+// cspReportSummaryResponse represents the violations of a directive by a
+// blocked URI in responses.
+type cspReportSummaryResponse struct {
+	EffectiveDirective string    `json:"effective_directive"`
+	BlockedURI         string    `json:"blocked_uri"`
+	Count              int       `json:"count"`
+	FirstSeen          time.Time `json:"first_seen"`
+	LastSeen           time.Time `json:"last_seen"`
+}
+
+// HandleCreateCSPReports handles Content Security Policy violation reports
+// sent by browsers, both to report-uri directives and through the Reporting
+// API. Reports are not authenticated, so only the fields needed to tighten
+// the policy are kept, truncated to a bounded length.
+//
+//	@Summary		Create CSP Reports
+//	@Description	Record Content Security Policy violation reports sent by browsers
+//	@Tags			security
+//	@Accept			json
+//	@Param			reports	body	legacyCSPReport	true	"Report, or a Reporting API batch"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Router			/csp-reports  [POST]
+func HandleCreateCSPReports(logger *slog.Logger, cspReportsCreator cspReportsCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Reports are small, larger bodies are rejected without reading them.
+		r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+
+		reports, err := decodeCSPReports(r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid csp reports",
+				slog.String("error", err.Error()),
+			)
+
+			responseProblems(w, r, validation.Problems{"body": "must be a CSP violation report"})
+			return
+		}
+
+		if err = cspReportsCreator.CreateCSPReports(ctx, reports); err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to create csp reports",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// decodeCSPReports decodes the reports in a request body in either format,
+// returning an error if it holds none.
+func decodeCSPReports(r *http.Request) ([]models.CSPReport, error) {
+	receivedAt := time.Now()
+	var reports []models.CSPReport
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/reports+json" {
+		var batch []reportingAPIReport
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+
+		for _, report := range batch {
+			if report.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, models.CSPReport{
+				DocumentURI:        report.Body.DocumentURL,
+				BlockedURI:         report.Body.BlockedURL,
+				EffectiveDirective: report.Body.EffectiveDirective,
+				Disposition:        report.Body.Disposition,
+				SourceFile:         report.Body.SourceFile,
+				LineNumber:         report.Body.LineNumber,
+			})
+		}
+	} else {
+		var report legacyCSPReport
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+
+		// Older browsers only send the violated directive, followed by its
+		// source list.
+		directive := report.Report.EffectiveDirective
+		if directive == "" {
+			directive, _, _ = strings.Cut(report.Report.ViolatedDirective, " ")
+		}
+
+		reports = append(reports, models.CSPReport{
+			DocumentURI:        report.Report.DocumentURI,
+			BlockedURI:         report.Report.BlockedURI,
+			EffectiveDirective: directive,
+			Disposition:        report.Report.Disposition,
+			SourceFile:         report.Report.SourceFile,
+			LineNumber:         report.Report.LineNumber,
+		})
+	}
+
+	valid := make([]models.CSPReport, 0, min(len(reports), maxCSPReportsPerRequest))
+	for _, report := range reports {
+		if len(valid) == maxCSPReportsPerRequest {
+			break
+		}
+		if report.DocumentURI == "" || report.EffectiveDirective == "" {
+			continue
+		}
+		if report.Disposition != "report" {
+			report.Disposition = "enforce"
+		}
+
+		report.DocumentURI = truncate(report.DocumentURI, maxCSPReportFieldLength)
+		report.BlockedURI = truncate(report.BlockedURI, maxCSPReportFieldLength)
+		report.EffectiveDirective = truncate(report.EffectiveDirective, 64)
+		report.SourceFile = truncate(report.SourceFile, maxCSPReportFieldLength)
+		report.ReceivedAt = receivedAt
+
+		valid = append(valid, report)
+	}
+
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("no csp violation reports")
+	}
+	return valid, nil
+}
+
+// truncate shortens s to at most n bytes, without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// HandleCSPReportSummary handles the request for the most frequent Content
+// Security Policy violations, to find what the policy still needs to allow
+// before it is tightened.
+//
+//	@Summary		CSP Report Summary
+//	@Description	Count CSP violations by directive and blocked URI, most frequent first
+//	@Tags			admin
+//	@Produce		json
+//	@Param			since	query		string	false	"RFC 3339 time to count reports from, defaults to 7 days ago"
+//	@Param			limit	query		int		false	"Maximum number of violations to return, up to 100"
+//	@Success		200		{array}		cspReportSummaryResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		403		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/csp-reports  [GET]
+func HandleCSPReportSummary(logger *slog.Logger, cspReportsSummarizer cspReportsSummarizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		since := params.QueryTime("since", time.Now().AddDate(0, 0, -7))
+		limit := params.QueryInt("limit", defaultListLimit, 1, maxListLimit)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		summaries, err := cspReportsSummarizer.SummarizeCSPReports(ctx, since, limit)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to summarize csp reports",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		response := make([]cspReportSummaryResponse, len(summaries))
+		for i, summary := range summaries {
+			response[i] = cspReportSummaryResponse{
+				EffectiveDirective: summary.EffectiveDirective,
+				BlockedURI:         summary.BlockedURI,
+				Count:              summary.Count,
+				FirstSeen:          summary.FirstSeen,
+				LastSeen:           summary.LastSeen,
+			}
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
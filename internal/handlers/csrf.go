@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// csrfTokenIssuer represents a type capable of issuing a CSRF token, setting
+// it as a cookie on the response.
+type csrfTokenIssuer interface {
+	Issue(w http.ResponseWriter) (string, error)
+}
+
+// csrfTokenResponse represents the response for issuing a CSRF token.
+type csrfTokenResponse struct {
+	Token string `json:"token"`
+}
+
+// HandleCSRFToken handles the request for a CSRF token, which browser clients
+// send in the X-CSRF-Token header of requests that change state.
+//
+//	@Summary		CSRF Token
+//	@Description	Issue a CSRF token for browser clients, also set as a cookie. Send it in the X-CSRF-Token header of POST, PUT and DELETE requests made without a bearer token, including login and signup, with credentials included from other origins. Clients other than browsers do not need one.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	csrfTokenResponse
+//	@Failure		500	{object}	problem.Details
+//	@Router			/csrf  [GET]
+func HandleCSRFToken(logger *slog.Logger, csrfTokenIssuer csrfTokenIssuer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		token, err := csrfTokenIssuer.Issue(w)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to issue csrf token",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		responseJSON(ctx, logger, w, http.StatusOK, csrfTokenResponse{Token: token})
+	})
+}
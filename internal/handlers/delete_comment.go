@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// commentReader represents a type capable of reading a comment from storage
+// and returning it or an error.
+type commentReader interface {
+	ReadComment(ctx context.Context, postID uint64, id uint64) (models.Comment, error)
+}
+
+// commentDeleter represents a type capable of deleting a comment from storage
+// and returning an error if it could not be deleted.
+type commentDeleter interface {
+	DeleteComment(ctx context.Context, postID uint64, id uint64) error
+}
+
+// HandleDeleteComment handles the delete comment request. Comments can be
+// deleted by their author and by the author of the post they are on.
+//
+//	@Summary		Delete Comment
+//	@Description	Delete a comment on a post by ID
+//	@Tags			comment
+//	@Param			id			path	string	true	"Post ID"
+//	@Param			commentID	path	string	true	"Comment ID"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}/comments/{commentID}  [DELETE]
+func HandleDeleteComment(
+	logger *slog.Logger,
+	postReader postReader,
+	commentReader commentReader,
+	commentDeleter commentDeleter,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the ids from the path parameters
+		params := validation.NewParams(r)
+		postID := params.PathID("id")
+		id := params.PathID("commentID")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		comment, err := commentReader.ReadComment(ctx, postID, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read comment")
+			return
+		}
+
+		// Users can delete their own comments and moderate the comments on
+		// their own posts
+		userID, _ := auth.UserIDFromContext(ctx)
+		if userID != comment.AuthorID {
+			post, err := postReader.ReadPost(ctx, postID)
+			if err != nil {
+				responseError(ctx, logger, w, r, err, "failed to read post")
+				return
+			}
+
+			if userID != post.AuthorID {
+				securityEvents.Emit(ctx, events.Event{
+					Type:     events.TypePermissionDenied,
+					Severity: events.SeverityWarning,
+					Message:  "user tried to delete another user's comment",
+					Attrs: map[string]string{
+						"post_id":    strconv.FormatUint(postID, 10),
+						"comment_id": strconv.FormatUint(id, 10),
+					},
+				})
+
+				problem.Error(w, r, http.StatusForbidden, "Only the author of a comment or of its post can delete it")
+				return
+			}
+		}
+
+		// Delete the comment
+		if err := commentDeleter.DeleteComment(ctx, postID, id); err != nil {
+			responseError(ctx, logger, w, r, err, "failed to delete comment")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
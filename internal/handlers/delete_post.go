@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postDeleter represents a type capable of deleting a post from storage and
+// returning an error if it could not be deleted.
+type postDeleter interface {
+	DeletePost(ctx context.Context, id uint64) error
+}
+
+// HandleDeletePost handles the delete post request. Only the author of a post
+// can delete it.
+//
+//	@Summary		Delete Post
+//	@Description	Delete Post by ID
+//	@Tags			post
+//	@Param			id	path	string	true	"Post ID"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}  [DELETE]
+func HandleDeletePost(
+	logger *slog.Logger,
+	postReader postReader,
+	postDeleter postDeleter,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		// Users can only delete their own posts
+		if userID, _ := auth.UserIDFromContext(ctx); userID != post.AuthorID {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to delete another author's post",
+				Attrs:    map[string]string{"post_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Only the author of a post can delete it")
+			return
+		}
+
+		// Delete the post
+		if err := postDeleter.DeletePost(ctx, id); err != nil {
+			responseError(ctx, logger, w, r, err, "failed to delete post")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
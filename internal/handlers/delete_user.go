@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// userDeleter represents a type capable of deleting a user from storage and
+// returning an error if it could not be deleted.
+type userDeleter interface {
+	DeleteUser(ctx context.Context, id uint64) error
+}
+
+// HandleDeleteUser handles the delete user request.
+//
+//	@Summary		Delete User
+//	@Description	Delete User by ID
+//	@Tags			user
+//	@Param			id	path	string	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/users/{id}  [DELETE]
+func HandleDeleteUser(logger *slog.Logger, userDeleter userDeleter, securityEvents securityEventEmitter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// Users can only delete their own account
+		if userID, _ := auth.UserIDFromContext(ctx); uint64(userID) != id {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to delete another account",
+				Attrs:    map[string]string{"target_user_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Users can only delete their own account")
+			return
+		}
+
+		// Delete the user
+		if err := userDeleter.DeleteUser(ctx, id); err != nil {
+			responseError(ctx, logger, w, r, err, "failed to delete user")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
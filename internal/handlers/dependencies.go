@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// dependenciesReader represents a type capable of detecting the versions and
+// optional features of the application's dependencies.
+type dependenciesReader interface {
+	ReadDependencies(ctx context.Context) (models.Dependencies, error)
+}
+
+// extensionResponse represents a database extension in responses.
+type extensionResponse struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// databaseInfoResponse represents the database server in responses.
+type databaseInfoResponse struct {
+	Driver     string              `json:"driver"`
+	Version    string              `json:"version"`
+	Extensions []extensionResponse `json:"extensions"`
+}
+
+// dependenciesResponse represents the response for the dependencies request.
+type dependenciesResponse struct {
+	Database databaseInfoResponse `json:"database"`
+	// Features reports, by feature name, whether the feature is available.
+	Features map[string]bool `json:"features"`
+}
+
+// HandleDependencies handles the request for the versions and optional
+// features of the application's dependencies.
+//
+//	@Summary		Dependencies
+//	@Description	Database version, installed extensions and available optional features
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	dependenciesResponse
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/dependencies  [GET]
+func HandleDependencies(logger *slog.Logger, dependenciesReader dependenciesReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		dependencies, err := dependenciesReader.ReadDependencies(ctx)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read dependencies")
+			return
+		}
+
+		extensions := make([]extensionResponse, len(dependencies.Database.Extensions))
+		for i, extension := range dependencies.Database.Extensions {
+			extensions[i] = extensionResponse{
+				Name:    extension.Name,
+				Version: extension.Version,
+			}
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, dependenciesResponse{
+			Database: databaseInfoResponse{
+				Driver:     dependencies.Database.Driver,
+				Version:    dependencies.Database.Version,
+				Extensions: extensions,
+			},
+			Features: dependencies.Features,
+		})
+	})
+}
@@ -3,8 +3,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
 )
 
 // validator is an object that can be validated.
@@ -12,14 +21,84 @@ type validator interface {
 	// Valid checks the object and returns any
 	// problems. If len(problems) == 0 then
 	// the object is valid.
-	Valid(ctx context.Context) (problems map[string]string)
+	Valid(ctx context.Context) (problems validation.Problems)
+}
+
+// securityEventEmitter represents a type capable of recording security
+// events, such as failed logins and permission denials.
+type securityEventEmitter interface {
+	Emit(ctx context.Context, event events.Event)
+}
+
+// featureDetector represents a type capable of reporting whether an optional
+// database feature, such as an extension, is available.
+type featureDetector interface {
+	HasFeature(ctx context.Context, feature string) bool
+}
+
+// serviceErrors maps the errors returned by services to the status code and
+// detail they are reported to clients with.
+var serviceErrors = []struct {
+	err    error
+	status int
+	detail string
+}{
+	{services.ErrNotFound, http.StatusNotFound, "The requested resource does not exist"},
+	{services.ErrInvalidCredentials, http.StatusUnauthorized, "The email address or password is incorrect"},
+	{services.ErrEmailTaken, http.StatusConflict, "The email address is already in use"},
+	{services.ErrSummariesDisabled, http.StatusServiceUnavailable, "Summaries are not enabled on this server"},
+	{services.ErrSummaryFailed, http.StatusBadGateway, "The language model could not summarize the post"},
+}
+
+// errorStatus returns the status code and detail an error returned by a
+// service is reported with. Errors without a mapping are internal server
+// errors, whose details are never shown to clients.
+func errorStatus(err error) (int, string) {
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.detail
+		}
+	}
+	return http.StatusInternalServerError, ""
+}
+
+// responseError writes the problem response for an error returned by a
+// service. Internal server errors are logged with message, as the client is not
+// told what went wrong.
+func responseError(ctx context.Context, logger *slog.Logger, w http.ResponseWriter, r *http.Request, err error, message string) {
+	status, detail := errorStatus(err)
+	if status >= http.StatusInternalServerError {
+		logger.ErrorContext(
+			ctx,
+			message,
+			slog.String("error", err.Error()),
+		)
+	}
+
+	problem.Error(w, r, status, detail)
+}
+
+// responseProblems writes the problem response for a request that failed
+// validation, listing what is wrong with each invalid field.
+func responseProblems(w http.ResponseWriter, r *http.Request, problems validation.Problems) {
+	details := problem.New(r, http.StatusBadRequest, "The request has invalid fields")
+	details.Problems = problems
+	problem.Write(w, details)
 }
 
 // decodeValid decodes a model from an http request and performs validation
-// on it.
-func decodeValid[T validator](r *http.Request) (T, map[string]string, error) {
+// on it. Unknown fields are rejected when the request's route group decodes
+// strictly, and reported as problems.
+func decodeValid[T validator](r *http.Request) (T, validation.Problems, error) {
 	var v T
-	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+	decoder := json.NewDecoder(r.Body)
+	if validation.StrictDecoding(r.Context()) {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&v); err != nil {
+		if field, ok := unknownField(err); ok {
+			return v, validation.Problems{field: "is not a known field"}, fmt.Errorf("decode json: %w", err)
+		}
 		return v, nil, fmt.Errorf("decode json: %w", err)
 	}
 	if problems := v.Valid(r.Context()); len(problems) > 0 {
@@ -27,3 +106,18 @@ func decodeValid[T validator](r *http.Request) (T, map[string]string, error) {
 	}
 	return v, nil, nil
 }
+
+// unknownField returns the field named by a decoding error caused by an
+// unknown field. encoding/json only reports these through the error message.
+func unknownField(err error) (string, bool) {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+
+	field, err := strconv.Unquote(quoted)
+	if err != nil {
+		return "", false
+	}
+	return field, true
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/health"
+)
+
+// healthHistoryReader represents a type capable of returning the recent
+// results of dependency health checks.
+type healthHistoryReader interface {
+	History() []health.Result
+}
+
+// healthHistoryResponse represents the response for the health history
+// request.
+type healthHistoryResponse struct {
+	Results []health.Result `json:"results"`
+	// Flaps counts, per dependency, how many times its health changed within
+	// the returned results.
+	Flaps map[string]int `json:"flaps"`
+}
+
+// HandleHealthHistory handles the health history request.
+//
+//	@Summary		Health History
+//	@Description	Recent dependency health check results, oldest first
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	healthHistoryResponse
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/health/history  [GET]
+func HandleHealthHistory(logger *slog.Logger, healthHistoryReader healthHistoryReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := healthHistoryReader.History()
+
+		response := healthHistoryResponse{
+			Results: results,
+			Flaps:   make(map[string]int),
+		}
+
+		last := make(map[string]bool)
+		for _, result := range results {
+			if healthy, ok := last[result.Dependency]; ok && healthy != result.Healthy {
+				response.Flaps[result.Dependency]++
+			}
+			last[result.Dependency] = result.Healthy
+		}
+
+		responseJSON(r.Context(), logger, w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/health"
+)
+
+// healthChecker represents a type capable of checking the current health of
+// the service's dependencies.
+type healthChecker interface {
+	CheckNow(ctx context.Context) []health.Result
+}
+
+// healthzResponse represents the response for the health check request.
+type healthzResponse struct {
+	Status string          `json:"status"`
+	Checks []health.Result `json:"checks"`
+}
+
+// HandleHealthz handles the health check request used by orchestrator
+// liveness and readiness probes. It responds with a 503 when any dependency is
+// unhealthy.
+func HandleHealthz(logger *slog.Logger, healthChecker healthChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := healthChecker.CheckNow(r.Context())
+
+		status := http.StatusOK
+		response := healthzResponse{Status: "ok", Checks: results}
+		for _, result := range results {
+			if !result.Healthy {
+				status = http.StatusServiceUnavailable
+				response.Status = "unavailable"
+				break
+			}
+		}
+
+		responseJSON(r.Context(), logger, w, status, response)
+	})
+}
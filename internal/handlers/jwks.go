@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/auth"
+)
+
+// keySetPublisher represents a type capable of listing the public keys tokens
+// are verified with.
+type keySetPublisher interface {
+	JWKS() auth.JWKSet
+}
+
+// HandleJWKS handles requests for the public keys bearer tokens are signed
+// with, so other services can verify them without sharing a secret.
+//
+// It is served outside of /api, at the path verifiers expect, so it is not
+// part of the swagger spec.
+func HandleJWKS(logger *slog.Logger, keySetPublisher keySetPublisher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Keys are published before they become active, so verifiers caching
+		// the set for a few minutes still see a new key before tokens use it.
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		responseJSON(r.Context(), logger, w, http.StatusOK, keySetPublisher.JWKS())
+	})
+}
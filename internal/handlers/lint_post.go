@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/lint"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postLinter represents a type capable of checking the body of a post draft
+// for problems.
+type postLinter interface {
+	Lint(ctx context.Context, body string) (lint.Result, error)
+}
+
+// lintFinding represents a problem found in a post draft.
+type lintFinding struct {
+	// Rule is one of "broken-markdown", "missing-alt-text", "long-paragraph"
+	// or "dead-link".
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// lintPostResponse represents the response for linting a post.
+type lintPostResponse struct {
+	Findings []lintFinding `json:"findings"`
+	// Complete is false when the time budget ran out before every link was
+	// checked.
+	Complete bool `json:"complete"`
+}
+
+// HandleLintPost handles the lint post request, checking the body of a post
+// for broken Markdown, images without alt text, overly long paragraphs and
+// links to posts or users that do not exist. Only the author of a post can
+// lint it.
+//
+//	@Summary		Lint Post
+//	@Description	Check a post draft by ID for problems
+//	@Tags			post
+//	@Produce		json
+//	@Param			id	path		string	true	"Post ID"
+//	@Success		200	{object}	lintPostResponse
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}/lint  [POST]
+func HandleLintPost(
+	logger *slog.Logger,
+	postReader postReader,
+	postLinter postLinter,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		// Drafts are only for their author to see
+		if userID, _ := auth.UserIDFromContext(ctx); userID != post.AuthorID {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to lint another author's post",
+				Attrs:    map[string]string{"post_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Only the author of a post can lint it")
+			return
+		}
+
+		result, err := postLinter.Lint(ctx, post.Body)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to lint post")
+			return
+		}
+		if !result.Complete {
+			logger.WarnContext(ctx, "Lint budget ran out", slog.Uint64("post_id", id))
+		}
+
+		findings := make([]lintFinding, len(result.Findings))
+		for i, finding := range result.Findings {
+			findings[i] = lintFinding{
+				Rule:    finding.Rule,
+				Line:    finding.Line,
+				Message: finding.Message,
+			}
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, lintPostResponse{
+			Findings: findings,
+			Complete: result.Complete,
+		})
+	})
+}
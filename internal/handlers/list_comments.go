@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// commentsLister represents a type capable of listing a page of the comments
+// on a post and the total number of comments on it.
+type commentsLister interface {
+	ListComments(ctx context.Context, postID uint64, opts services.ListCommentsOptions) ([]models.Comment, int, error)
+}
+
+// listCommentsResponse represents the response for listing the comments on a
+// post.
+type listCommentsResponse struct {
+	Comments []commentResponse `json:"comments"`
+	Total    int               `json:"total"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+// HandleListComments handles the request for the comments on a post.
+//
+//	@Summary		List Comments
+//	@Description	List a page of the comments on a post, oldest first
+//	@Tags			comment
+//	@Produce		json
+//	@Param			id		path		string	true	"Post ID"
+//	@Param			limit	query		int		false	"Maximum number of comments to return, up to 100"
+//	@Param			offset	query		int		false	"Number of comments to skip"
+//	@Success		200		{object}	listCommentsResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		404		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/posts/{id}/comments  [GET]
+func HandleListComments(logger *slog.Logger, commentsLister commentsLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path and the paging options from the query string
+		params := validation.NewParams(r)
+		postID := params.PathID("id")
+		opts := parseListCommentsOptions(params)
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		comments, total, err := commentsLister.ListComments(ctx, postID, opts)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to list comments")
+			return
+		}
+
+		response := listCommentsResponse{
+			Comments: make([]commentResponse, len(comments)),
+			Total:    total,
+			Limit:    opts.Limit,
+			Offset:   opts.Offset,
+		}
+		for i, comment := range comments {
+			response.Comments[i] = newCommentResponse(comment)
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// parseListCommentsOptions reads the paging options from the query string,
+// recording any problems with them in params.
+func parseListCommentsOptions(params *validation.Params) services.ListCommentsOptions {
+	return services.ListCommentsOptions{
+		Limit:  params.QueryInt("limit", defaultListLimit, 1, maxListLimit),
+		Offset: params.QueryInt("offset", 0, 0, math.MaxInt),
+	}
+}
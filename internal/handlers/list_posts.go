@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postsLister represents a type capable of listing a page of posts and the
+// total number of posts matching the filters.
+type postsLister interface {
+	ListPosts(ctx context.Context, opts services.ListPostsOptions) ([]models.Post, int, error)
+}
+
+// listPostsResponse represents the response for listing posts.
+type listPostsResponse struct {
+	Posts  []postResponse `json:"posts"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// HandleListPosts handles the list posts request.
+//
+//	@Summary		List Posts
+//	@Description	List a page of posts, newest first, optionally by a single author
+//	@Tags			post
+//	@Produce		json
+//	@Param			limit		query		int	false	"Maximum number of posts to return, up to 100"
+//	@Param			offset		query		int	false	"Number of posts to skip"
+//	@Param			author_id	query		int	false	"Only posts written by the user"
+//	@Success		200			{object}	listPostsResponse
+//	@Failure		400			{object}	problem.Details
+//	@Failure		500			{object}	problem.Details
+//	@Router			/posts  [GET]
+func HandleListPosts(logger *slog.Logger, postsLister postsLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		opts := parseListPostsOptions(params)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		posts, total, err := postsLister.ListPosts(ctx, opts)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to list posts",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		response := listPostsResponse{
+			Posts:  make([]postResponse, len(posts)),
+			Total:  total,
+			Limit:  opts.Limit,
+			Offset: opts.Offset,
+		}
+		for i, post := range posts {
+			response.Posts[i] = newPostResponse(post)
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// parseListPostsOptions reads the paging and filtering options from the query
+// string, recording any problems with them in params.
+func parseListPostsOptions(params *validation.Params) services.ListPostsOptions {
+	return services.ListPostsOptions{
+		Limit:    params.QueryInt("limit", defaultListLimit, 1, maxListLimit),
+		Offset:   params.QueryInt("offset", 0, 0, math.MaxInt),
+		AuthorID: params.QueryID("author_id"),
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Number of users, posts or comments returned per page when no limit is given, and the
+// largest limit accepted.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// usersLister represents a type capable of listing a page of users and the
+// total number of users matching the filters.
+type usersLister interface {
+	ListUsers(ctx context.Context, opts services.ListUsersOptions) ([]models.User, int, error)
+}
+
+// listUsersResponse represents the response for listing users.
+type listUsersResponse struct {
+	Users  []userResponse `json:"users"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
+// HandleListUsers handles the list users request. Listing a canary user raises
+// a critical security event, as with reading one.
+//
+//	@Summary		List Users
+//	@Description	List a page of users, optionally filtered and sorted
+//	@Tags			user
+//	@Produce		json
+//	@Param			limit	query		int		false	"Maximum number of users to return, up to 100"
+//	@Param			offset	query		int		false	"Number of users to skip"
+//	@Param			sort	query		string	false	"Field to sort by, prefixed with - for descending order"
+//	@Param			name	query		string	false	"Only users whose name contains the value"
+//	@Param			email	query		string	false	"Only users with the email address"
+//	@Success		200		{object}	listUsersResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/users  [GET]
+func HandleListUsers(
+	logger *slog.Logger,
+	usersLister usersLister,
+	canaryIDLister canaryIDLister,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		opts := parseListUsersOptions(params)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		users, total, err := usersLister.ListUsers(ctx, opts)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to list users",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		// Raise an alert when a canary is listed
+		reportCanaryReads(ctx, logger, canaryIDLister, securityEvents, users, "canary user listed")
+
+		response := listUsersResponse{
+			Users:  make([]userResponse, len(users)),
+			Total:  total,
+			Limit:  opts.Limit,
+			Offset: opts.Offset,
+		}
+		for i, user := range users {
+			response.Users[i] = userResponse{
+				ID:        user.ID,
+				Name:      user.Name,
+				Email:     user.Email,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			}
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// parseListUsersOptions reads the paging, sorting and filtering options from
+// the query string, recording any problems with them in params.
+func parseListUsersOptions(params *validation.Params) services.ListUsersOptions {
+	opts := services.ListUsersOptions{
+		Limit:  params.QueryInt("limit", defaultListLimit, 1, maxListLimit),
+		Offset: params.QueryInt("offset", 0, 0, math.MaxInt),
+		Name:   params.Query("name"),
+		Email:  params.Query("email"),
+	}
+
+	if sort := params.Query("sort"); sort != "" {
+		opts.Sort, opts.Desc = strings.CutPrefix(sort, "-")
+		params.Problems.Check(
+			services.ValidUserSort(opts.Sort),
+			"sort",
+			"must be one of id, name, created_at or updated_at, optionally prefixed with -",
+		)
+	}
+
+	return opts
+}
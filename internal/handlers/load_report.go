@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/overload"
+)
+
+// loadReporter represents a type capable of reporting the server's load.
+type loadReporter interface {
+	Stats() overload.Stats
+}
+
+// HandleLoadReport handles the load report request.
+//
+//	@Summary		Load Report
+//	@Description	Current load and the number of requests shed per priority
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	overload.Stats
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/load  [GET]
+func HandleLoadReport(logger *slog.Logger, loadReporter loadReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseJSON(r.Context(), logger, w, http.StatusOK, loadReporter.Stats())
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// credentialsVerifier represents a type capable of finding the user matching
+// an email address and password.
+type credentialsVerifier interface {
+	VerifyPassword(ctx context.Context, email string, password string) (models.User, error)
+}
+
+// canaryEmailChecker represents a type capable of reporting whether an email
+// address belongs to a canary user.
+type canaryEmailChecker interface {
+	IsCanaryEmail(ctx context.Context, email string) (bool, error)
+}
+
+// tokenIssuer represents a type capable of issuing a signed token for a user.
+type tokenIssuer interface {
+	Issue(userID uint) (string, time.Time, error)
+}
+
+// loginRequest represents the request for logging in.
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Valid checks that the email address and password are set.
+func (req loginRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("email", req.Email, validation.Required())
+	problems.Field("password", req.Password, validation.Required())
+
+	return problems
+}
+
+// loginResponse represents the response for logging in.
+type loginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// HandleLogin handles the login request, exchanging an email address and
+// password for a bearer token. Failed logins are reported as security events.
+// Logins as a canary user raise a critical event and are rejected like a wrong
+// password, so the attacker cannot tell the credentials were a decoy.
+//
+//	@Summary		Login
+//	@Description	Exchange an email address and password for a bearer token
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credentials	body		loginRequest	true	"Credentials"
+//	@Success		200			{object}	loginResponse
+//	@Failure		400			{object}	problem.Details
+//	@Failure		401			{object}	problem.Details
+//	@Failure		500			{object}	problem.Details
+//	@Router			/login  [POST]
+func HandleLogin(
+	logger *slog.Logger,
+	credentialsVerifier credentialsVerifier,
+	tokenIssuer tokenIssuer,
+	canaryEmailChecker canaryEmailChecker,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[loginRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid login request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be valid JSON credentials"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Check whether the email belongs to a canary. Failing to check is
+		// logged rather than failing the login.
+		canary, err := canaryEmailChecker.IsCanaryEmail(ctx, request.Email)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to check for canary",
+				slog.String("error", err.Error()),
+			)
+		}
+		if canary {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypeCanaryTriggered,
+				Severity: events.SeverityCritical,
+				Message:  "login attempted as a canary user",
+				Attrs:    map[string]string{"email": request.Email},
+			})
+		}
+
+		// Check the credentials. Unknown emails and wrong passwords get the
+		// same response so valid emails cannot be discovered. Canary
+		// credentials are still checked so the response takes as long.
+		user, err := credentialsVerifier.VerifyPassword(ctx, request.Email, request.Password)
+		if err == nil && canary {
+			err = services.ErrInvalidCredentials
+		}
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidCredentials) && !canary {
+				securityEvents.Emit(ctx, events.Event{
+					Type:     events.TypeLoginFailed,
+					Severity: events.SeverityWarning,
+					Message:  "failed login",
+					Attrs:    map[string]string{"email": request.Email},
+				})
+			}
+
+			responseError(ctx, logger, w, r, err, "failed to verify credentials")
+			return
+		}
+
+		// Issue a token for the user
+		token, expiresAt, err := tokenIssuer.Issue(user.ID)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to issue token",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, loginResponse{
+			Token:     token,
+			TokenType: "Bearer",
+			ExpiresAt: expiresAt,
+		})
+	})
+}
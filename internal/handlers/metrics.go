@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/metrics"
+)
+
+// HandleMetrics handles Prometheus scrapes, writing every metric in the text
+// exposition format.
+//
+// It is served outside of /api, at the path Prometheus scrapes by default, so
+// it is not part of the swagger spec.
+func HandleMetrics(logger *slog.Logger, metricsWriter io.WriterTo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metrics.ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		if _, err := metricsWriter.WriteTo(w); err != nil {
+			logger.ErrorContext(
+				r.Context(),
+				"failed to write metrics",
+				slog.String("error", err.Error()),
+			)
+		}
+	})
+}
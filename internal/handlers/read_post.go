@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postReader represents a type capable of reading a post from storage and
+// returning it or an error.
+type postReader interface {
+	ReadPost(ctx context.Context, id uint64) (models.Post, error)
+}
+
+// HandleReadPost handles the read post request.
+//
+//	@Summary		Read Post
+//	@Description	Read Post by ID
+//	@Tags			post
+//	@Produce		json
+//	@Param			id	path		string	true	"Post ID"
+//	@Success		200	{object}	postResponse
+//	@Failure		400	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Router			/posts/{id}  [GET]
+func HandleReadPost(logger *slog.Logger, postReader postReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// Read the post
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, newPostResponse(post))
+	})
+}
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
 )
 
 // userReader represents a type capable of reading a user from storage and
@@ -16,15 +20,58 @@ type userReader interface {
 	ReadUser(ctx context.Context, id uint64) (models.User, error)
 }
 
-// readUserResponse represents the response for reading a user.
-type readUserResponse struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+// canaryChecker represents a type capable of reporting whether a user is a
+// canary.
+type canaryChecker interface {
+	IsCanary(ctx context.Context, userID uint64) (bool, error)
 }
 
-// HandleReadUser handles the read user request.
+// canaryIDLister represents a type capable of listing the ids of the canary
+// users.
+type canaryIDLister interface {
+	CanaryUserIDs(ctx context.Context) ([]uint, error)
+}
+
+// reportCanaryReads raises a critical security event for every canary among
+// the users a request returned, as no one has a reason to read them. Failing to
+// list the canaries is only logged, so the users are still served.
+func reportCanaryReads(
+	ctx context.Context,
+	logger *slog.Logger,
+	canaryIDLister canaryIDLister,
+	securityEvents securityEventEmitter,
+	users []models.User,
+	message string,
+) {
+	if len(users) == 0 {
+		return
+	}
+
+	canaryIDs, err := canaryIDLister.CanaryUserIDs(ctx)
+	if err != nil {
+		logger.ErrorContext(
+			ctx,
+			"failed to list canaries",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, user := range users {
+		if slices.Contains(canaryIDs, user.ID) {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypeCanaryTriggered,
+				Severity: events.SeverityCritical,
+				Message:  message,
+				Attrs:    map[string]string{"target_user_id": strconv.FormatUint(uint64(user.ID), 10)},
+			})
+		}
+	}
+}
+
+// HandleReadUser handles the read user request. Reading a canary user raises a
+// critical security event, as no one has a reason to, but is otherwise served
+// like any other user so scrapers do not notice.
 //
 //	@Summary		Read User
 //	@Description	Read User by ID
@@ -32,51 +79,62 @@ type readUserResponse struct {
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		string	true	"User ID"
-//	@Success		200	{object}	uint
-//	@Failure		400	{object}	string
-//	@Failure		404	{object}	string
-//	@Failure		500	{object}	string
+//	@Success		200	{object}	userResponse
+//	@Failure		400	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
 //	@Router			/users/{id}  [GET]
-func HandleReadUser(logger *slog.Logger, userReader userReader) http.Handler {
+func HandleReadUser(
+	logger *slog.Logger,
+	userReader userReader,
+	canaryChecker canaryChecker,
+	securityEvents securityEventEmitter,
+) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		// Read id from path parameters
-		idStr := r.PathValue("id")
-
-		// Convert the ID from string to int
-		id, err := strconv.Atoi(idStr)
-		if err != nil {
-			logger.ErrorContext(
-				r.Context(),
-				"failed to parse id from url",
-				slog.String("id", idStr),
-				slog.String("error", err.Error()),
-			)
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
 
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			responseProblems(w, r, params.Problems)
 			return
 		}
 
 		// Read the user
-		user, err := userReader.ReadUser(ctx, uint64(id))
+		user, err := userReader.ReadUser(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read user")
+			return
+		}
+
+		// Raise an alert when a canary is read
+		canary, err := canaryChecker.IsCanary(ctx, id)
 		if err != nil {
 			logger.ErrorContext(
-				r.Context(),
-				"failed to read user",
+				ctx,
+				"failed to check for canary",
 				slog.String("error", err.Error()),
 			)
-
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+		}
+		if canary {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypeCanaryTriggered,
+				Severity: events.SeverityCritical,
+				Message:  "canary user read",
+				Attrs:    map[string]string{"target_user_id": strconv.FormatUint(id, 10)},
+			})
 		}
 
 		// Convert our models.User domain model into a response model.
-		response := readUserResponse{
-			ID:       user.ID,
-			Name:     user.Name,
-			Email:    user.Email,
-			Password: user.Password,
+		response := userResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
 		}
 
 		// Encode the response model as JSON
@@ -88,7 +146,7 @@ func HandleReadUser(logger *slog.Logger, userReader userReader) http.Handler {
 				"failed to encode response",
 				slog.String("error", err.Error()))
 
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			problem.Error(w, r, http.StatusInternalServerError, "")
 		}
 	})
 }
@@ -103,6 +161,7 @@ func responseJSON(ctx context.Context, logger *slog.Logger, w http.ResponseWrite
 			"failed to encode response",
 			slog.String("error", err.Error()))
 
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		// The status has already been sent, so all that can be done is log the
+		// failure.
 	}
 }
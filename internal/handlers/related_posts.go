@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Number of related posts returned when no limit is given.
+const defaultRelatedLimit = 5
+
+// relatedPostsLister represents a type capable of listing the posts related to
+// a post and reporting how they were related.
+type relatedPostsLister interface {
+	ListRelatedPosts(ctx context.Context, id uint64, limit int, vector bool) ([]models.Post, string, error)
+}
+
+// relatedPostsResponse represents the response for listing related posts.
+type relatedPostsResponse struct {
+	Posts []postResponse `json:"posts"`
+	// Mode is how posts were related: vector, full_text or author.
+	Mode string `json:"mode"`
+}
+
+// HandleRelatedPosts handles the related posts request. Embeddings are
+// compared when the vector extension is installed and the post has one,
+// falling back to the best matching the database supports otherwise.
+//
+//	@Summary		Related Posts
+//	@Description	List the posts most related to a post
+//	@Tags			post
+//	@Produce		json
+//	@Param			id		path		string	true	"Post ID"
+//	@Param			limit	query		int		false	"Maximum number of posts to return, up to 100"
+//	@Success		200		{object}	relatedPostsResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		404		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/posts/{id}/related  [GET]
+func HandleRelatedPosts(logger *slog.Logger, relatedPostsLister relatedPostsLister, featureDetector featureDetector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		limit := params.QueryInt("limit", defaultRelatedLimit, 1, maxListLimit)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		vector := featureDetector.HasFeature(ctx, models.FeatureVectorSearch)
+		posts, mode, err := relatedPostsLister.ListRelatedPosts(ctx, id, limit, vector)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to list related posts")
+			return
+		}
+
+		response := relatedPostsResponse{
+			Posts: make([]postResponse, len(posts)),
+			Mode:  mode,
+		}
+		for i, post := range posts {
+			response.Posts[i] = newPostResponse(post)
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
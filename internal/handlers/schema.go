@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/jsonschema"
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// schemaBodies holds the request and response bodies of each resource, keyed
+// by resource and then by body name. Their schemas are served by
+// HandleSchema, so bodies must be added here as handlers are added.
+var schemaBodies = map[string]map[string]any{
+	"announcements": {
+		"request":  announcementRequest{},
+		"response": announcementResponse{},
+	},
+	"canaries": {
+		"credentialsResponse": canaryCredentialsResponse{},
+		"response":            canaryResponse{},
+	},
+	"comments": {
+		"createRequest": createCommentRequest{},
+		"listResponse":  listCommentsResponse{},
+		"response":      commentResponse{},
+	},
+	"events": {
+		"createRequest":  createEventsRequest{},
+		"createResponse": createEventsResponse{},
+	},
+	"login": {
+		"request":  loginRequest{},
+		"response": loginResponse{},
+	},
+	"posts": {
+		"createRequest":          createPostRequest{},
+		"lintResponse":           lintPostResponse{},
+		"listResponse":           listPostsResponse{},
+		"relatedResponse":        relatedPostsResponse{},
+		"response":               postResponse{},
+		"searchResponse":         semanticSearchPostsResponse{},
+		"summarizeResponse":      summarizePostResponse{},
+		"updateEmbeddingRequest": updatePostEmbeddingRequest{},
+		"updateRequest":          updatePostRequest{},
+	},
+	"settings": {
+		"updateRequest": settingsRequest{},
+		"response":      settingsResponse{},
+	},
+	"sync": {
+		"response": syncResponse{},
+	},
+	"users": {
+		"createRequest":  createUserRequest{},
+		"createResponse": userResponse{},
+		"listResponse":   listUsersResponse{},
+		"readResponse":   userResponse{},
+		"searchResponse": searchUsersResponse{},
+		"updateRequest":  updateUserRequest{},
+		"updateResponse": userResponse{},
+	},
+}
+
+// HandleSchema handles the request for the JSON Schema of a resource's request
+// and response bodies, which are listed under $defs.
+//
+//	@Summary		Resource Schema
+//	@Description	JSON Schema of a resource's request and response bodies
+//	@Tags			schema
+//	@Produce		json
+//	@Param			resource	path		string	true	"Resource name, e.g. users"
+//	@Success		200			{object}	jsonschema.Schema
+//	@Failure		404			{object}	problem.Details
+//	@Router			/schema/{resource}  [GET]
+func HandleSchema(logger *slog.Logger) http.Handler {
+	// The bodies don't change, so the schemas are only generated once.
+	schemas := make(map[string]*jsonschema.Schema, len(schemaBodies))
+	for resource, bodies := range schemaBodies {
+		schema := &jsonschema.Schema{
+			Schema: jsonschema.Draft,
+			ID:     "/api/schema/" + resource,
+			Title:  resource,
+			Defs:   make(map[string]*jsonschema.Schema, len(bodies)),
+		}
+		for name, body := range bodies {
+			schema.Defs[name] = jsonschema.For(body)
+		}
+		schemas[resource] = schema
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schema, ok := schemas[r.PathValue("resource")]
+		if !ok {
+			problem.Error(w, r, http.StatusNotFound, "There is no schema for the resource")
+			return
+		}
+
+		responseJSON(r.Context(), logger, w, http.StatusOK, schema)
+	})
+}
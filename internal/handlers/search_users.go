@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Number of users returned by a search when no limit is given.
+const defaultSearchLimit = 10
+
+// usersSearcher represents a type capable of searching users by name and
+// reporting how they were matched.
+type usersSearcher interface {
+	SearchUsers(ctx context.Context, query string, limit int, trigram bool) ([]models.User, string, error)
+}
+
+// searchUsersResponse represents the response for searching users.
+type searchUsersResponse struct {
+	Users []userResponse `json:"users"`
+	// Mode is how users were matched: trigram, full_text or substring.
+	Mode string `json:"mode"`
+}
+
+// HandleSearchUsers handles the search users request. Names are matched
+// fuzzily when the pg_trgm extension is installed, falling back to the best
+// matching the database supports otherwise. Finding a canary user raises a
+// critical security event, as with reading one.
+//
+//	@Summary		Search Users
+//	@Description	Search users by name, best matches first
+//	@Tags			user
+//	@Produce		json
+//	@Param			q		query		string	true	"Name to search for"
+//	@Param			limit	query		int		false	"Maximum number of users to return, up to 100"
+//	@Success		200		{object}	searchUsersResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/users/search  [GET]
+func HandleSearchUsers(
+	logger *slog.Logger,
+	usersSearcher usersSearcher,
+	featureDetector featureDetector,
+	canaryIDLister canaryIDLister,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		query := params.Query("q", validation.MaxLength(100))
+		params.Problems.Check(query != "", "q", "is required")
+		limit := params.QueryInt("limit", defaultSearchLimit, 1, maxListLimit)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		trigram := featureDetector.HasFeature(ctx, models.FeatureTrigramSearch)
+		users, mode, err := usersSearcher.SearchUsers(ctx, query, limit, trigram)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to search users",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		// Raise an alert when a canary is found
+		reportCanaryReads(ctx, logger, canaryIDLister, securityEvents, users, "canary user found by search")
+
+		response := searchUsersResponse{
+			Users: make([]userResponse, len(users)),
+			Mode:  mode,
+		}
+		for i, user := range users {
+			response.Users[i] = userResponse{
+				ID:        user.ID,
+				Name:      user.Name,
+				Email:     user.Email,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			}
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
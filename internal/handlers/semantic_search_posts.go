@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postsSearcher represents a type capable of searching posts, by embedding
+// when one is given, and reporting how they were matched.
+type postsSearcher interface {
+	SearchPosts(ctx context.Context, query string, embedding []float32, limit int) ([]models.Post, string, error)
+}
+
+// queryEmbedder represents a type capable of generating the embeddings of
+// texts.
+type queryEmbedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// semanticSearchPostsResponse represents the response for searching posts.
+type semanticSearchPostsResponse struct {
+	Posts []postResponse `json:"posts"`
+	// Mode is how posts were matched: vector, full_text or substring.
+	Mode string `json:"mode"`
+}
+
+// HandleSemanticSearchPosts handles the semantic search posts request. The
+// query is embedded and compared to the embeddings of posts when an embedder
+// is configured and the vector extension is installed, falling back to the
+// best text matching the database supports otherwise. queryEmbedder may be
+// nil.
+//
+//	@Summary		Semantic Search Posts
+//	@Description	Search posts by meaning, best matches first
+//	@Tags			post
+//	@Produce		json
+//	@Param			q		query		string	true	"Text to search for"
+//	@Param			limit	query		int		false	"Maximum number of posts to return, up to 100"
+//	@Success		200		{object}	semanticSearchPostsResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Router			/posts/semantic-search  [GET]
+func HandleSemanticSearchPosts(
+	logger *slog.Logger,
+	postsSearcher postsSearcher,
+	queryEmbedder queryEmbedder,
+	featureDetector featureDetector,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		query := params.Query("q", validation.MaxLength(1000))
+		params.Problems.Check(query != "", "q", "is required")
+		limit := params.QueryInt("limit", defaultSearchLimit, 1, maxListLimit)
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// A failing provider degrades the search rather than failing it
+		var embedding []float32
+		if queryEmbedder != nil && featureDetector.HasFeature(ctx, models.FeatureVectorSearch) {
+			vectors, err := queryEmbedder.Embed(ctx, []string{query})
+			if err != nil {
+				logger.WarnContext(
+					ctx,
+					"failed to embed search query, falling back to text search",
+					slog.String("error", err.Error()),
+				)
+			} else {
+				embedding = vectors[0]
+			}
+		}
+
+		posts, mode, err := postsSearcher.SearchPosts(ctx, query, embedding, limit)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to search posts",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		response := semanticSearchPostsResponse{
+			Posts: make([]postResponse, len(posts)),
+			Mode:  mode,
+		}
+		for i, post := range posts {
+			response.Posts[i] = newPostResponse(post)
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// themeTokenName matches the names of theme tokens, which are used as CSS
+// custom property names by the frontend.
+var themeTokenName = regexp.MustCompile(`^[a-z][a-z0-9-]{0,63}$`)
+
+// maxThemeTokens is the largest number of theme tokens accepted.
+const maxThemeTokens = 100
+
+// settingsReader represents a type capable of reading the blog settings.
+type settingsReader interface {
+	ReadSettings(ctx context.Context) (models.Settings, error)
+}
+
+// settingsUpdater represents a type capable of replacing the blog settings.
+type settingsUpdater interface {
+	UpdateSettings(ctx context.Context, settings models.Settings) (models.Settings, error)
+}
+
+// settingsRequest represents the request for replacing the blog settings.
+type settingsRequest struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Theme       map[string]string `json:"theme"`
+	SocialLinks map[string]string `json:"social_links"`
+}
+
+// Valid checks the lengths of the text fields, the theme token names and that
+// social links are absolute http(s) URLs.
+func (req settingsRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("title", req.Title, validation.Length(1, 100))
+	problems.Field("description", req.Description, validation.MaxLength(500))
+
+	problems.Check(len(req.Theme) <= maxThemeTokens, "theme", fmt.Sprintf("must have at most %d tokens", maxThemeTokens))
+	for name, value := range req.Theme {
+		problems.Field("theme."+name, name, validation.Matches(themeTokenName, "name must be lowercase letters, digits and dashes"))
+		problems.Field("theme."+name, value, validation.MaxLength(128))
+	}
+
+	for name, link := range req.SocialLinks {
+		problems.Field("social_links."+name, link, validation.AbsoluteURL("http", "https"))
+	}
+
+	return problems
+}
+
+// settingsResponse represents the response for reading or replacing the blog
+// settings.
+type settingsResponse struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Theme       map[string]string `json:"theme"`
+	SocialLinks map[string]string `json:"social_links"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// newSettingsResponse converts a models.Settings domain model into a response
+// model.
+func newSettingsResponse(settings models.Settings) settingsResponse {
+	response := settingsResponse{
+		Title:       settings.Title,
+		Description: settings.Description,
+		Theme:       settings.Theme,
+		SocialLinks: settings.SocialLinks,
+		UpdatedAt:   settings.UpdatedAt,
+	}
+
+	// Clients can rely on the maps being present.
+	if response.Theme == nil {
+		response.Theme = map[string]string{}
+	}
+	if response.SocialLinks == nil {
+		response.SocialLinks = map[string]string{}
+	}
+
+	return response
+}
+
+// HandleReadSettings handles the read settings request.
+//
+//	@Summary		Read Settings
+//	@Description	Read the blog-level settings
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	settingsResponse
+//	@Failure		500	{object}	problem.Details
+//	@Router			/admin/settings  [GET]
+func HandleReadSettings(logger *slog.Logger, settingsReader settingsReader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		settings, err := settingsReader.ReadSettings(r.Context())
+		if err != nil {
+			logger.ErrorContext(
+				r.Context(),
+				"failed to read settings",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(r.Context(), logger, w, http.StatusOK, newSettingsResponse(settings))
+	})
+}
+
+// HandleUpdateSettings handles the update settings request, replacing every
+// setting.
+//
+//	@Summary		Update Settings
+//	@Description	Replace the blog-level settings
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			settings	body		settingsRequest	true	"Settings"
+//	@Success		200			{object}	settingsResponse
+//	@Failure		400			{object}	problem.Details
+//	@Failure		401			{object}	problem.Details
+//	@Failure		403			{object}	problem.Details
+//	@Failure		500			{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/settings  [PUT]
+func HandleUpdateSettings(logger *slog.Logger, settingsUpdater settingsUpdater) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[settingsRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid update settings request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON settings object"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		settings, err := settingsUpdater.UpdateSettings(ctx, models.Settings{
+			Title:       request.Title,
+			Description: request.Description,
+			Theme:       request.Theme,
+			SocialLinks: request.SocialLinks,
+		})
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to update settings",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, newSettingsResponse(settings))
+	})
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jha-captech/blog/internal/slo"
+)
+
+// sloReporter represents a type capable of reporting SLO attainment over a
+// window.
+type sloReporter interface {
+	Report(window time.Duration) slo.Report
+}
+
+// sloWindows are the rolling windows included in the SLO report.
+var sloWindows = []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour}
+
+// HandleSLOReport handles the SLO report request.
+//
+//	@Summary		SLO Report
+//	@Description	Availability and latency SLO attainment over rolling windows
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{array}		slo.Report
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/admin/slo  [GET]
+func HandleSLOReport(logger *slog.Logger, sloReporter sloReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reports := make([]slo.Report, len(sloWindows))
+		for i, window := range sloWindows {
+			reports[i] = sloReporter.Report(window)
+		}
+
+		responseJSON(r.Context(), logger, w, http.StatusOK, reports)
+	})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postSummarizer represents a type capable of summarizing a post, storing the
+// summary on it and reporting whether it was cached.
+type postSummarizer interface {
+	SummarizePost(ctx context.Context, post models.Post) (models.Post, bool, error)
+}
+
+// summarizePostResponse represents the response for summarizing a post.
+type summarizePostResponse struct {
+	postResponse
+	// Cached is set when the summary was reused rather than generated.
+	Cached bool `json:"cached"`
+}
+
+// HandleSummarizePost handles the summarize post request, generating a summary
+// of the post with the language model for use as its meta description. Only
+// the author of a post can summarize it.
+//
+//	@Summary		Summarize Post
+//	@Description	Generate and store a summary of a post by ID
+//	@Tags			post
+//	@Produce		json
+//	@Param			id	path		string	true	"Post ID"
+//	@Success		200	{object}	summarizePostResponse
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Failure		502	{object}	problem.Details
+//	@Failure		503	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}/summarize  [POST]
+func HandleSummarizePost(
+	logger *slog.Logger,
+	postReader postReader,
+	postSummarizer postSummarizer,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		// Summaries cost money, so only authors can ask for them
+		if userID, _ := auth.UserIDFromContext(ctx); userID != post.AuthorID {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to summarize another author's post",
+				Attrs:    map[string]string{"post_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Only the author of a post can summarize it")
+			return
+		}
+
+		post, cached, err := postSummarizer.SummarizePost(ctx, post)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to summarize post")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, summarizePostResponse{
+			postResponse: newPostResponse(post),
+			Cached:       cached,
+		})
+	})
+}
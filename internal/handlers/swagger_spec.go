@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"io/fs"
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// HandleSwaggerSpec handles requests for the OpenAPI spec, serving
+// swagger.json from the provided file system.
+func HandleSwaggerSpec(logger *slog.Logger, files fs.FS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spec, err := fs.ReadFile(files, "swagger.json")
+		if err != nil {
+			logger.ErrorContext(
+				r.Context(),
+				"failed to read swagger spec",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(spec)
+	})
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Number of users returned by a sync request when no limit is given, and the
+// largest limit accepted.
+const (
+	defaultSyncLimit = 100
+	maxSyncLimit     = 500
+)
+
+// usersSyncer represents a type capable of listing the users changed after a
+// change sequence number.
+type usersSyncer interface {
+	ListUsersChangedSince(ctx context.Context, since uint64, limit int) ([]models.UserChange, error)
+}
+
+// syncResponse represents the response for a sync request.
+type syncResponse struct {
+	Users []userResponse `json:"users"`
+	// DeletedUserIDs holds the ids of the users deleted since the token, which
+	// clients should remove from their copy.
+	DeletedUserIDs []uint `json:"deleted_user_ids"`
+	// NextToken is passed as since on the next request to receive the changes
+	// made after this response.
+	NextToken string `json:"next_token"`
+	// HasMore is set when more changes are available right away.
+	HasMore bool `json:"has_more"`
+}
+
+// HandleSync handles the request for the entities changed since a sync token,
+// letting clients keep a local copy up to date without refetching everything.
+// Requests without a token return every entity from the start. Changes are
+// followed by their sequence number rather than a timestamp, so none are
+// missed when several are made within the precision of the clock. Syncing a
+// canary user raises a critical security event, as with reading one.
+//
+//	@Summary		Sync
+//	@Description	List users created, updated or deleted since a sync token
+//	@Tags			sync
+//	@Produce		json
+//	@Param			since	query		string	false	"Sync token from a previous response"
+//	@Param			limit	query		int		false	"Maximum number of changes to return"
+//	@Success		200		{object}	syncResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		403		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/sync  [GET]
+func HandleSync(
+	logger *slog.Logger,
+	usersSyncer usersSyncer,
+	canaryIDLister canaryIDLister,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		params := validation.NewParams(r)
+		limit := params.QueryInt("limit", defaultSyncLimit, 1, maxSyncLimit)
+
+		since, err := decodeSyncToken(params.Query("since"))
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid sync token",
+				slog.String("error", err.Error()),
+			)
+
+			params.Problems.Add("since", "must be a sync token from a previous response")
+		}
+
+		if len(params.Problems) > 0 {
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// One extra change is read to tell whether more are waiting.
+		changes, err := usersSyncer.ListUsersChangedSince(ctx, since, limit+1)
+		if err != nil {
+			logger.ErrorContext(
+				ctx,
+				"failed to list changed users",
+				slog.String("error", err.Error()),
+			)
+
+			problem.Error(w, r, http.StatusInternalServerError, "")
+			return
+		}
+
+		response := syncResponse{
+			Users:          make([]userResponse, 0, min(len(changes), limit)),
+			DeletedUserIDs: make([]uint, 0),
+			NextToken:      encodeSyncToken(since),
+			HasMore:        len(changes) > limit,
+		}
+
+		users := make([]models.User, 0, len(response.Users))
+		for i, change := range changes {
+			if i == limit {
+				break
+			}
+
+			if change.Deleted {
+				response.DeletedUserIDs = append(response.DeletedUserIDs, change.UserID)
+			} else {
+				users = append(users, change.User)
+				response.Users = append(response.Users, userResponse{
+					ID:        change.User.ID,
+					Name:      change.User.Name,
+					Email:     change.User.Email,
+					CreatedAt: change.User.CreatedAt,
+					UpdatedAt: change.User.UpdatedAt,
+				})
+			}
+			response.NextToken = encodeSyncToken(change.Seq)
+		}
+
+		// Raise an alert when a canary is synced
+		reportCanaryReads(ctx, logger, canaryIDLister, securityEvents, users, "canary user synced")
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
+
+// encodeSyncToken encodes the sequence number of the last change a client
+// received into an opaque token.
+func encodeSyncToken(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(seq, 10)))
+}
+
+// decodeSyncToken decodes a token created by encodeSyncToken. An empty token
+// decodes to zero, which is before every change.
+func decodeSyncToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("decode token: %w", err)
+	}
+
+	seq, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse token: %w", err)
+	}
+
+	return seq, nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// postUpdater represents a type capable of updating a post in storage and
+// returning it or an error.
+type postUpdater interface {
+	UpdatePost(ctx context.Context, id uint64, patch models.Post) (models.Post, error)
+}
+
+// updatePostRequest represents the request for updating a post. The author of
+// a post cannot be changed.
+type updatePostRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Valid checks that the title and body are neither empty nor too long.
+func (req updatePostRequest) Valid(ctx context.Context) validation.Problems {
+	return validPostContent(req.Title, req.Body)
+}
+
+// HandleUpdatePost handles the update post request. Only the author of a post
+// can update it.
+//
+//	@Summary		Update Post
+//	@Description	Update Post by ID
+//	@Tags			post
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Post ID"
+//	@Param			post	body		updatePostRequest	true	"Post"
+//	@Success		200		{object}	postResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		403		{object}	problem.Details
+//	@Failure		404		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}  [PUT]
+func HandleUpdatePost(
+	logger *slog.Logger,
+	postReader postReader,
+	postUpdater postUpdater,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		// Users can only change their own posts
+		if userID, _ := auth.UserIDFromContext(ctx); userID != post.AuthorID {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to change another author's post",
+				Attrs:    map[string]string{"post_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Only the author of a post can change it")
+			return
+		}
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[updatePostRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid update post request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON post"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Update the post
+		post, err = postUpdater.UpdatePost(ctx, id, models.Post{
+			Title: strings.TrimSpace(request.Title),
+			Body:  request.Body,
+		})
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to update post")
+			return
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, newPostResponse(post))
+	})
+}
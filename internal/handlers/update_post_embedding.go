@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// Largest embedding accepted, matching the largest vector pgvector can index.
+const maxEmbeddingDimensions = 2000
+
+// postEmbeddingSetter represents a type capable of storing the embedding of a
+// post.
+type postEmbeddingSetter interface {
+	SetPostEmbedding(ctx context.Context, id uint64, embedding []float32) error
+}
+
+// updatePostEmbeddingRequest represents the request for setting the embedding
+// of a post, computed by the client with a model of its choosing.
+type updatePostEmbeddingRequest struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// Valid checks that the embedding is neither empty nor too large.
+func (req updatePostEmbeddingRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Check(
+		len(req.Embedding) > 0 && len(req.Embedding) <= maxEmbeddingDimensions,
+		"embedding",
+		fmt.Sprintf("must have between 1 and %d dimensions", maxEmbeddingDimensions),
+	)
+
+	return problems
+}
+
+// HandleUpdatePostEmbedding handles the update post embedding request. Posts
+// with embeddings are related by them when the vector extension is installed.
+// Only the author of a post can set its embedding.
+//
+//	@Summary		Update Post Embedding
+//	@Description	Set the embedding posts are related by
+//	@Tags			post
+//	@Accept			json
+//	@Param			id			path	string						true	"Post ID"
+//	@Param			embedding	body	updatePostEmbeddingRequest	true	"Embedding"
+//	@Success		204
+//	@Failure		400	{object}	problem.Details
+//	@Failure		401	{object}	problem.Details
+//	@Failure		403	{object}	problem.Details
+//	@Failure		404	{object}	problem.Details
+//	@Failure		500	{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/posts/{id}/embedding  [PUT]
+func HandleUpdatePostEmbedding(
+	logger *slog.Logger,
+	postReader postReader,
+	postEmbeddingSetter postEmbeddingSetter,
+	securityEvents securityEventEmitter,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		post, err := postReader.ReadPost(ctx, id)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to read post")
+			return
+		}
+
+		// Embeddings decide which posts are shown as related, so only authors
+		// can set them
+		if userID, _ := auth.UserIDFromContext(ctx); userID != post.AuthorID {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to set the embedding of another author's post",
+				Attrs:    map[string]string{"post_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Only the author of a post can set its embedding")
+			return
+		}
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[updatePostEmbeddingRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid update post embedding request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON embedding"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Store the embedding
+		if err := postEmbeddingSetter.SetPostEmbedding(ctx, id, request.Embedding); err != nil {
+			responseError(ctx, logger, w, r, err, "failed to update post embedding")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// userUpdater represents a type capable of updating a user in storage and
+// returning it or an error.
+type userUpdater interface {
+	UpdateUser(ctx context.Context, id uint64, patch models.User, password string) (models.User, error)
+}
+
+// updateUserRequest represents the request for updating a user. The password
+// is left unchanged when omitted.
+type updateUserRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
+// Valid checks that the name and email are set, the email address is well
+// formed, and a new password is long enough.
+func (req updateUserRequest) Valid(ctx context.Context) validation.Problems {
+	problems := make(validation.Problems)
+
+	problems.Field("name", strings.TrimSpace(req.Name), validation.Required(), validation.MaxLength(100))
+	problems.Field("email", req.Email, validation.Required(), validation.Email())
+	if req.Password != "" {
+		problems.Field("password", req.Password, passwordRules...)
+	}
+
+	return problems
+}
+
+// HandleUpdateUser handles the update user request.
+//
+//	@Summary		Update User
+//	@Description	Update User by ID
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"User ID"
+//	@Param			user	body		updateUserRequest	true	"User"
+//	@Success		200		{object}	userResponse
+//	@Failure		400		{object}	problem.Details
+//	@Failure		401		{object}	problem.Details
+//	@Failure		403		{object}	problem.Details
+//	@Failure		404		{object}	problem.Details
+//	@Failure		409		{object}	problem.Details
+//	@Failure		500		{object}	problem.Details
+//	@Security		BearerAuth
+//	@Router			/users/{id}  [PUT]
+func HandleUpdateUser(logger *slog.Logger, userUpdater userUpdater, securityEvents securityEventEmitter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// Read the id from the path parameters
+		params := validation.NewParams(r)
+		id := params.PathID("id")
+		if len(params.Problems) > 0 {
+			logger.WarnContext(ctx, "invalid request parameters", slog.Any("problems", params.Problems))
+
+			responseProblems(w, r, params.Problems)
+			return
+		}
+
+		// Users can only change their own account
+		if userID, _ := auth.UserIDFromContext(ctx); uint64(userID) != id {
+			securityEvents.Emit(ctx, events.Event{
+				Type:     events.TypePermissionDenied,
+				Severity: events.SeverityWarning,
+				Message:  "user tried to change another account",
+				Attrs:    map[string]string{"target_user_id": strconv.FormatUint(id, 10)},
+			})
+
+			problem.Error(w, r, http.StatusForbidden, "Users can only change their own account")
+			return
+		}
+
+		// Decode and validate the request body
+		request, problems, err := decodeValid[updateUserRequest](r)
+		if err != nil {
+			logger.WarnContext(
+				ctx,
+				"invalid update user request",
+				slog.String("error", err.Error()),
+			)
+
+			if problems == nil {
+				problems = validation.Problems{"body": "must be a valid JSON user"}
+			}
+			responseProblems(w, r, problems)
+			return
+		}
+
+		// Update the user
+		user, err := userUpdater.UpdateUser(ctx, id, models.User{
+			Name:  strings.TrimSpace(request.Name),
+			Email: request.Email,
+		}, request.Password)
+		if err != nil {
+			responseError(ctx, logger, w, r, err, "failed to update user")
+			return
+		}
+
+		// Convert our models.User domain model into a response model. The
+		// password is never sent back.
+		response := userResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+
+		responseJSON(ctx, logger, w, http.StatusOK, response)
+	})
+}
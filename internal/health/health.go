@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// Checker checks the health of a single dependency, returning an error when it
+// is unhealthy.
+type Checker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+// CheckHealth calls f(ctx).
+func (f CheckerFunc) CheckHealth(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the outcome of a single check of a dependency.
+type Result struct {
+	Dependency string        `json:"dependency"`
+	Healthy    bool          `json:"healthy"`
+	Error      string        `json:"error,omitempty"`
+	CheckedAt  time.Time     `json:"checked_at"`
+	Duration   time.Duration `json:"duration_ns"`
+}
+
+// Monitor periodically checks a set of dependencies and keeps the most recent
+// results in a fixed size ring buffer, so intermittent failures can be seen
+// after the fact.
+type Monitor struct {
+	logger   *slog.Logger
+	clock    clock.Clock
+	checkers map[string]Checker
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	history []Result
+	next    int
+	full    bool
+	latest  map[string]Result
+}
+
+// NewMonitor creates a new Monitor keeping up to size results and returns a
+// pointer to it.
+func NewMonitor(logger *slog.Logger, clk clock.Clock, size int, timeout time.Duration, checkers map[string]Checker) *Monitor {
+	return &Monitor{
+		logger:   logger,
+		clock:    clk,
+		checkers: checkers,
+		timeout:  timeout,
+		history:  make([]Result, size),
+		latest:   make(map[string]Result, len(checkers)),
+	}
+}
+
+// Run checks every dependency once per interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.CheckAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every dependency once, recording the results.
+func (m *Monitor) CheckAll(ctx context.Context) {
+	for name, checker := range m.checkers {
+		m.record(m.check(ctx, name, checker))
+	}
+}
+
+// CheckNow checks every dependency once and returns the results without
+// recording them, for callers that need the current state such as readiness
+// probes.
+func (m *Monitor) CheckNow(ctx context.Context) []Result {
+	results := make([]Result, 0, len(m.checkers))
+	for name, checker := range m.checkers {
+		results = append(results, m.check(ctx, name, checker))
+	}
+	return results
+}
+
+// check runs a single checker with the configured timeout.
+func (m *Monitor) check(ctx context.Context, name string, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := m.clock.Now()
+	err := checker.CheckHealth(ctx)
+
+	result := Result{
+		Dependency: name,
+		Healthy:    err == nil,
+		CheckedAt:  start,
+		Duration:   m.clock.Now().Sub(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// record adds a result to the history, logging changes in a dependency's
+// health.
+func (m *Monitor) record(result Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if previous, ok := m.latest[result.Dependency]; ok && previous.Healthy != result.Healthy {
+		m.logger.Warn(
+			"Dependency health changed",
+			slog.String("dependency", result.Dependency),
+			slog.Bool("healthy", result.Healthy),
+			slog.String("error", result.Error),
+		)
+	}
+	m.latest[result.Dependency] = result
+
+	if len(m.history) == 0 {
+		return
+	}
+
+	m.history[m.next] = result
+	m.next = (m.next + 1) % len(m.history)
+	if m.next == 0 {
+		m.full = true
+	}
+}
+
+// History returns the recorded results, oldest first.
+func (m *Monitor) History() []Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.full {
+		return append([]Result(nil), m.history[:m.next]...)
+	}
+
+	return append(append([]Result(nil), m.history[m.next:]...), m.history[:m.next]...)
+}
+
+// Latest returns the most recent result for every dependency.
+func (m *Monitor) Latest() map[string]Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latest := make(map[string]Result, len(m.latest))
+	for name, result := range m.latest {
+		latest[name] = result
+	}
+	return latest
+}
@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// breaker is a consecutive failure circuit breaker. Once threshold failures
+// happen in a row it opens and rejects requests until cooldown has passed, at
+// which point a single trial request is let through. A successful trial closes
+// the breaker again while a failed one re-opens it.
+type breaker struct {
+	clock     clock.Clock
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// allow reports whether a request may be sent.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if b.trial || b.clock.Now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+
+	b.trial = true
+	return true
+}
+
+// release gives up a permission returned by allow without recording an outcome.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// success records a successful request, closing the breaker.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// failure records a failed request, opening the breaker once the threshold is
+// reached.
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// cancelBody cancels the request context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
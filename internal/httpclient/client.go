@@ -0,0 +1,162 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+var (
+	// ErrCircuitOpen is returned when the circuit breaker for a host is open and
+	// requests to that host are being rejected without being sent.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+
+	// ErrHostBusy is returned when the concurrency limit for a host was not freed
+	// up before the request's timeout budget ran out.
+	ErrHostBusy = errors.New("host concurrency limit reached")
+)
+
+// Options holds the settings used to construct a Client.
+type Options struct {
+	// Timeout is the total time budget for a single request, including the time
+	// spent waiting for a free slot on the host.
+	Timeout time.Duration
+
+	// MaxConnsPerHost is the maximum number of in-flight requests to a single
+	// host.
+	MaxConnsPerHost int
+
+	// FailureThreshold is the number of consecutive failures after which the
+	// circuit breaker for a host opens.
+	FailureThreshold int
+
+	// Cooldown is how long an open circuit breaker waits before allowing a
+	// trial request through.
+	Cooldown time.Duration
+
+	// Clock is used to time circuit breaker cooldowns. Defaults to the system
+	// clock.
+	Clock clock.Clock
+}
+
+// Client is an outbound http client shared by webhook and third-party
+// integrations. It limits concurrency per host, applies a timeout budget to
+// every request, and stops calling hosts that keep failing.
+type Client struct {
+	httpClient *http.Client
+	options    Options
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// New creates a new Client and returns a pointer to it. Zero values in options
+// are replaced with defaults.
+func New(options Options) *Client {
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Second
+	}
+	if options.MaxConnsPerHost <= 0 {
+		options.MaxConnsPerHost = 10
+	}
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 30 * time.Second
+	}
+	if options.Clock == nil {
+		options.Clock = clock.Real{}
+	}
+
+	return &Client{
+		httpClient: &http.Client{},
+		options:    options,
+		hosts:      make(map[string]*host),
+	}
+}
+
+// Do sends the provided request, returning the response or an error. Responses
+// with a 5xx status code are returned to the caller but count as a failure for
+// the host's circuit breaker.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	h := c.host(req.URL.Host)
+
+	if !h.breaker.allow() {
+		return nil, fmt.Errorf("[in httpclient.Client.Do] %s: %w", req.URL.Host, ErrCircuitOpen)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.options.Timeout)
+
+	// Wait for a free slot on the host, giving up once the budget is spent or
+	// the caller gives up.
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		cancel()
+		h.breaker.release()
+		if err := req.Context().Err(); err != nil {
+			return nil, fmt.Errorf("[in httpclient.Client.Do] %s: %w", req.URL.Host, err)
+		}
+		return nil, fmt.Errorf("[in httpclient.Client.Do] %s: %w", req.URL.Host, ErrHostBusy)
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	<-h.slots
+
+	if err != nil {
+		cancel()
+		// Requests the caller gave up on say nothing about the host's health.
+		if req.Context().Err() != nil {
+			h.breaker.release()
+		} else {
+			h.breaker.failure()
+		}
+		return nil, fmt.Errorf("[in httpclient.Client.Do] failed to send request: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		h.breaker.failure()
+	} else {
+		h.breaker.success()
+	}
+
+	// The timeout budget also covers reading the body, so the context is
+	// cancelled when the caller closes it.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// host returns the state tracked for the provided host, creating it on first
+// use.
+func (c *Client) host(name string) *host {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.hosts[name]
+	if !ok {
+		h = &host{
+			slots: make(chan struct{}, c.options.MaxConnsPerHost),
+			breaker: &breaker{
+				clock:     c.options.Clock,
+				threshold: c.options.FailureThreshold,
+				cooldown:  c.options.Cooldown,
+			},
+		}
+		c.hosts[name] = h
+	}
+
+	return h
+}
+
+// host holds the concurrency limit and circuit breaker for a single host.
+type host struct {
+	slots   chan struct{}
+	breaker *breaker
+}
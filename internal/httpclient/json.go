@@ -0,0 +1,56 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody is how much of an error response PostJSON reads into the
+// returned error.
+const maxErrorBody = 1 << 10
+
+// PostJSON posts in as JSON to url and decodes the JSON response into out. A
+// bearerToken, when set, is sent in the Authorization header. Responses with a
+// 4xx or 5xx status code are returned as an error including the start of their
+// body.
+func (c *Client) PostJSON(ctx context.Context, url string, bearerToken string, in any, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("[in httpclient.Client.PostJSON] failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("[in httpclient.Client.PostJSON] failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("[in httpclient.Client.PostJSON] %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf(
+			"[in httpclient.Client.PostJSON] %s responded with status %d: %s",
+			req.URL.Host,
+			resp.StatusCode,
+			bytes.TrimSpace(detail),
+		)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("[in httpclient.Client.PostJSON] failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// Layout of a generated id, from the most significant bit:
+//
+//	1 bit unused (always zero so ids are positive int64 values)
+//	41 bits milliseconds since Epoch
+//	10 bits worker id
+//	12 bits per millisecond sequence
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	// MaxWorkerID is the largest worker id a Generator can be configured with.
+	MaxWorkerID = 1<<workerBits - 1
+
+	maxSequence = 1<<sequenceBits - 1
+)
+
+// Epoch is the time generated ids count milliseconds from. Changing it would
+// break the ordering of ids generated before and after the change.
+var Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrClockMovedBackwards is returned when the clock reports a time before the
+// last generated id, which could otherwise produce duplicate ids.
+var ErrClockMovedBackwards = errors.New("clock moved backwards")
+
+// Generator produces unique, time sortable 64-bit ids without a round trip to
+// the database. Every process generating ids must use a different worker id.
+type Generator struct {
+	clock    clock.Clock
+	workerID int64
+
+	mu       sync.Mutex
+	lastMS   int64
+	sequence int64
+}
+
+// New creates a new Generator for the provided worker id and returns a pointer
+// to it.
+func New(workerID int, clk clock.Clock) (*Generator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("[in idgen.New] worker id %d must be between 0 and %d", workerID, MaxWorkerID)
+	}
+
+	return &Generator{
+		clock:    clk,
+		workerID: int64(workerID),
+	}, nil
+}
+
+// Next returns the next id. When more ids are requested in a millisecond than
+// the sequence allows, Next waits for the next millisecond.
+func (g *Generator) Next() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.clock.Now().Sub(Epoch).Milliseconds()
+	if ms < g.lastMS {
+		return 0, fmt.Errorf("[in idgen.Generator.Next] %w by %dms", ErrClockMovedBackwards, g.lastMS-ms)
+	}
+
+	if ms == g.lastMS {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted for this millisecond, wait for the next one.
+			for ms <= g.lastMS {
+				time.Sleep(100 * time.Microsecond)
+				ms = g.clock.Now().Sub(Epoch).Milliseconds()
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+
+	g.lastMS = ms
+
+	return ms<<(workerBits+sequenceBits) | g.workerID<<sequenceBits | g.sequence, nil
+}
+
+// Time returns the time an id was generated, to millisecond precision.
+func Time(id int64) time.Time {
+	return Epoch.Add(time.Duration(id>>(workerBits+sequenceBits)) * time.Millisecond)
+}
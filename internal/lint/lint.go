@@ -0,0 +1,266 @@
+// Package lint checks post drafts for Markdown mistakes, images without alt
+// text, paragraphs too long to read comfortably and links to posts or users
+// that do not exist.
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/services"
+)
+
+// Rules findings are reported under.
+const (
+	RuleBrokenMarkdown = "broken-markdown"
+	RuleMissingAltText = "missing-alt-text"
+	RuleLongParagraph  = "long-paragraph"
+	RuleDeadLink       = "dead-link"
+)
+
+// Finding is a problem found in a draft, on its 1-based Line.
+type Finding struct {
+	Rule    string
+	Line    int
+	Message string
+}
+
+// Result holds the findings of a draft. Complete is false when the time budget
+// ran out before every link was checked.
+type Result struct {
+	Findings []Finding
+	Complete bool
+}
+
+// PostReader represents a type capable of reading a post, returning
+// services.ErrNotFound when it does not exist.
+type PostReader interface {
+	ReadPost(ctx context.Context, id uint64) (models.Post, error)
+}
+
+// UserReader represents a type capable of reading a user, returning
+// services.ErrNotFound when it does not exist.
+type UserReader interface {
+	ReadUser(ctx context.Context, id uint64) (models.User, error)
+}
+
+// Linter checks drafts within a time budget. The Markdown checks are local,
+// the budget bounds the lookups of the posts and users linked to.
+type Linter struct {
+	posts             PostReader
+	users             UserReader
+	origin            string
+	budget            time.Duration
+	maxParagraphWords int
+}
+
+// New creates a new Linter and returns a pointer to it. Links to origin, the
+// address the blog is served from, are checked as internal links along with
+// relative ones.
+func New(posts PostReader, users UserReader, origin string, budget time.Duration, maxParagraphWords int) *Linter {
+	return &Linter{
+		posts:             posts,
+		users:             users,
+		origin:            origin,
+		budget:            budget,
+		maxParagraphWords: maxParagraphWords,
+	}
+}
+
+var (
+	// Images and links, with the text and destination captured. Titles after
+	// the destination are dropped.
+	imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]*)[^)]*\)`)
+	linkPattern  = regexp.MustCompile(`(?:^|[^!])\[[^\]]*\]\(\s*([^)\s]*)[^)]*\)`)
+	// Links missing their closing parenthesis
+	openLinkPattern  = regexp.MustCompile(`\]\([^)]*$`)
+	codeSpanPattern  = regexp.MustCompile("`[^`]*`")
+	htmlImagePattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	htmlAltPattern   = regexp.MustCompile(`(?i)\balt\s*=\s*("[^"]*\S[^"]*"|'[^']*\S[^']*'|[^\s"'>]+)`)
+	// Internal paths whose target can be looked up
+	postPathPattern = regexp.MustCompile(`^(?:/api)?/posts/([^/?#]+)/?$`)
+	userPathPattern = regexp.MustCompile(`^(?:/api)?/users/([^/?#]+)/?$`)
+	// Lines that start blocks other than paragraphs
+	blockPattern = regexp.MustCompile(`^\s*(?:#|>|[-*+]\s|\d+[.)]\s|\||<)`)
+)
+
+// link is an internal link found in a draft.
+type link struct {
+	line int
+	path string
+}
+
+// Lint checks the body of a draft. The findings are ordered by line, with the
+// dead links last.
+func (l *Linter) Lint(ctx context.Context, body string) (Result, error) {
+	budgetCtx, cancel := context.WithTimeout(ctx, l.budget)
+	defer cancel()
+
+	var (
+		findings  []Finding
+		links     []link
+		fence     string
+		fenceLine int
+		paragraph struct {
+			line  int
+			words int
+		}
+	)
+
+	endParagraph := func() {
+		if paragraph.words > l.maxParagraphWords {
+			findings = append(findings, Finding{
+				Rule:    RuleLongParagraph,
+				Line:    paragraph.line,
+				Message: fmt.Sprintf("Paragraph has %d words, split it to stay under %d", paragraph.words, l.maxParagraphWords),
+			})
+		}
+		paragraph.line, paragraph.words = 0, 0
+	}
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		n := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		// Code blocks are left as written
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			endParagraph()
+			fence, fenceLine = trimmed[:3], n
+			continue
+		}
+
+		if trimmed == "" {
+			endParagraph()
+			continue
+		}
+		if blockPattern.MatchString(line) {
+			endParagraph()
+		} else {
+			if paragraph.line == 0 {
+				paragraph.line = n
+			}
+			paragraph.words += len(strings.Fields(line))
+		}
+
+		if strings.Count(line, "`")%2 != 0 {
+			findings = append(findings, Finding{Rule: RuleBrokenMarkdown, Line: n, Message: "Inline code is missing its closing backtick"})
+			continue
+		}
+		text := codeSpanPattern.ReplaceAllString(line, "")
+
+		if openLinkPattern.MatchString(text) {
+			findings = append(findings, Finding{Rule: RuleBrokenMarkdown, Line: n, Message: "Link is missing its closing parenthesis"})
+		}
+		if strings.Count(text, "**")%2 != 0 {
+			findings = append(findings, Finding{Rule: RuleBrokenMarkdown, Line: n, Message: "Bold text is missing its closing **"})
+		}
+
+		for _, match := range imagePattern.FindAllStringSubmatch(text, -1) {
+			if strings.TrimSpace(match[1]) == "" {
+				findings = append(findings, Finding{Rule: RuleMissingAltText, Line: n, Message: "Image " + match[2] + " has no alt text"})
+			}
+		}
+		for _, tag := range htmlImagePattern.FindAllString(text, -1) {
+			if !htmlAltPattern.MatchString(tag) {
+				findings = append(findings, Finding{Rule: RuleMissingAltText, Line: n, Message: "Image has no alt text"})
+			}
+		}
+
+		for _, match := range linkPattern.FindAllStringSubmatch(text, -1) {
+			if path, ok := l.internalPath(match[1]); ok {
+				links = append(links, link{line: n, path: path})
+			}
+		}
+	}
+	endParagraph()
+
+	if fence != "" {
+		findings = append(findings, Finding{Rule: RuleBrokenMarkdown, Line: fenceLine, Message: "Code block is never closed"})
+	}
+
+	// Look up the linked posts and users until the budget runs out, once each
+	dead := make(map[string]bool)
+	for _, link := range links {
+		isDead, checked := dead[link.path]
+		if !checked {
+			var err error
+			isDead, err = l.isDead(budgetCtx, link.path)
+			switch {
+			case err == nil:
+			case ctx.Err() != nil:
+				return Result{}, fmt.Errorf("[in lint.Linter.Lint] %w", ctx.Err())
+			case budgetCtx.Err() != nil:
+				return Result{Findings: findings, Complete: false}, nil
+			default:
+				return Result{}, fmt.Errorf("[in lint.Linter.Lint] failed to check link %s: %w", link.path, err)
+			}
+			dead[link.path] = isDead
+		}
+
+		if isDead {
+			findings = append(findings, Finding{Rule: RuleDeadLink, Line: link.line, Message: "Link to " + link.path + " points to nothing"})
+		}
+	}
+
+	return Result{Findings: findings, Complete: true}, nil
+}
+
+// internalPath returns the path of a link destination when it points to the
+// blog, either as a relative path or through its origin.
+func (l *Linter) internalPath(destination string) (string, bool) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", false
+	}
+
+	if u.Scheme != "" || u.Host != "" {
+		origin, err := url.Parse(l.origin)
+		if err != nil || !strings.EqualFold(u.Host, origin.Host) {
+			return "", false
+		}
+	} else if !strings.HasPrefix(u.Path, "/") {
+		return "", false
+	}
+
+	return u.Path, true
+}
+
+// isDead reports whether an internal path names a post or user that does not
+// exist. Other paths are not checked.
+func (l *Linter) isDead(ctx context.Context, path string) (bool, error) {
+	var err error
+	if match := postPathPattern.FindStringSubmatch(path); match != nil {
+		id, parseErr := strconv.ParseUint(match[1], 10, 64)
+		if parseErr != nil {
+			return true, nil
+		}
+		_, err = l.posts.ReadPost(ctx, id)
+	} else if match := userPathPattern.FindStringSubmatch(path); match != nil {
+		id, parseErr := strconv.ParseUint(match[1], 10, 64)
+		if parseErr != nil {
+			return true, nil
+		}
+		_, err = l.users.ReadUser(ctx, id)
+	} else {
+		return false, nil
+	}
+
+	if errors.Is(err, services.ErrNotFound) {
+		return true, nil
+	}
+	return false, err
+}
@@ -0,0 +1,29 @@
+// Package llm generates text with a large language model through a pluggable
+// provider, such as a hosted API or a local model server.
+package llm
+
+import (
+	"context"
+)
+
+// Provider generates text with a language model.
+type Provider interface {
+	// Complete returns the model's reply to input, following instructions.
+	Complete(ctx context.Context, instructions string, input string) (string, error)
+}
+
+// message represents a single message of a chat, as used by both the OpenAI
+// and Ollama chat endpoints.
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chat returns the messages sending instructions as the system prompt and
+// input as the user's message.
+func chat(instructions string, input string) []message {
+	return []message{
+		{Role: "system", Content: instructions},
+		{Role: "user", Content: input},
+	}
+}
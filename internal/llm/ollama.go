@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/httpclient"
+)
+
+// Ollama generates text with a local Ollama model server, keeping post content
+// on infrastructure you run.
+type Ollama struct {
+	client *httpclient.Client
+	url    string
+	model  string
+}
+
+// NewOllama creates a new Ollama provider for the server at baseURL, e.g.
+// "http://localhost:11434", and returns a pointer to it.
+func NewOllama(client *httpclient.Client, baseURL string, model string) *Ollama {
+	return &Ollama{
+		client: client,
+		url:    strings.TrimSuffix(baseURL, "/") + "/api/chat",
+		model:  model,
+	}
+}
+
+// ollamaRequest represents the request body of the chat endpoint. Streaming is
+// turned off so the reply arrives as a single response.
+type ollamaRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+// ollamaResponse represents the response body of the chat endpoint.
+type ollamaResponse struct {
+	Message message `json:"message"`
+}
+
+// Complete returns the model's reply to input, following instructions.
+func (p *Ollama) Complete(ctx context.Context, instructions string, input string) (string, error) {
+	var resp ollamaResponse
+	err := p.client.PostJSON(ctx, p.url, "", ollamaRequest{Model: p.model, Messages: chat(instructions, input)}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("[in llm.Ollama.Complete] %w", err)
+	}
+
+	return resp.Message.Content, nil
+}
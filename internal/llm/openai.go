@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/httpclient"
+)
+
+// OpenAI generates text with the chat completions endpoint of the OpenAI API,
+// or of any server compatible with it, such as vLLM or LocalAI.
+type OpenAI struct {
+	client *httpclient.Client
+	url    string
+	model  string
+	apiKey string
+}
+
+// NewOpenAI creates a new OpenAI provider for the API at baseURL, e.g.
+// "https://api.openai.com/v1", and returns a pointer to it.
+func NewOpenAI(client *httpclient.Client, baseURL string, model string, apiKey string) *OpenAI {
+	return &OpenAI{
+		client: client,
+		url:    strings.TrimSuffix(baseURL, "/") + "/chat/completions",
+		model:  model,
+		apiKey: apiKey,
+	}
+}
+
+// openAIRequest represents the request body of the chat completions endpoint.
+type openAIRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+}
+
+// openAIResponse represents the response body of the chat completions
+// endpoint.
+type openAIResponse struct {
+	Choices []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+}
+
+// Complete returns the model's reply to input, following instructions.
+func (p *OpenAI) Complete(ctx context.Context, instructions string, input string) (string, error) {
+	var resp openAIResponse
+	err := p.client.PostJSON(ctx, p.url, p.apiKey, openAIRequest{Model: p.model, Messages: chat(instructions, input)}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("[in llm.OpenAI.Complete] %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("[in llm.OpenAI.Complete] provider returned no reply")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/jha-captech/blog/internal/config"
+)
+
+// NewWriter creates the writer for the configured output target. The returned
+// io.Closer must be closed on shutdown.
+func NewWriter(sink config.LogSink) (io.Writer, io.Closer, error) {
+	switch sink.Output {
+	case config.LogOutputStdout:
+		return os.Stdout, nopCloser{}, nil
+	case config.LogOutputFile:
+		f := &RotatingFile{
+			Path:       sink.FilePath,
+			MaxSize:    sink.FileMaxSize,
+			MaxAge:     sink.FileMaxAge,
+			MaxBackups: sink.FileMaxBackups,
+		}
+		return f, f, nil
+	case config.LogOutputSyslog:
+		w, err := dialSyslogWriter(sink)
+		if err != nil {
+			return nil, nil, err
+		}
+		return w, w, nil
+	default:
+		return nil, nil, fmt.Errorf("[in logging.NewWriter] unsupported output %q", sink.Output)
+	}
+}
+
+// NewLogger creates a JSON logger for the configured output target. Records
+// sent to syslog are given the priority matching their level, and records
+// logged while serving a request carry its request_id. The returned io.Closer
+// must be closed on shutdown.
+func NewLogger(sink config.LogSink, level slog.Leveler) (*slog.Logger, io.Closer, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	if sink.Output == config.LogOutputSyslog {
+		h, closer, err := dialSyslogHandler(sink, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return slog.New(NewRequestIDHandler(h)), closer, nil
+	}
+
+	w, closer, err := NewWriter(sink)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return slog.New(NewRequestIDHandler(slog.NewJSONHandler(w, opts))), closer, nil
+}
+
+// nopCloser is an io.Closer for writers that must not be closed.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
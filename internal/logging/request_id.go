@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jha-captech/blog/internal/requestid"
+)
+
+// RequestIDHandler is a slog.Handler wrapper that adds the request_id of the
+// request being served, so every log line written while serving it, including
+// those of services, can be found by the id. Records logged outside of a
+// request are passed through unchanged.
+type RequestIDHandler struct {
+	next slog.Handler
+}
+
+// NewRequestIDHandler wraps next in a RequestIDHandler and returns a pointer to
+// it.
+func NewRequestIDHandler(next slog.Handler) *RequestIDHandler {
+	return &RequestIDHandler{next: next}
+}
+
+func (h *RequestIDHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RequestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *RequestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RequestIDHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *RequestIDHandler) WithGroup(name string) slog.Handler {
+	return &RequestIDHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFile is an io.Writer that writes to a file, rotating it once it
+// grows past MaxSize bytes or becomes older than MaxAge. Rotated files are
+// renamed with a timestamp suffix, and only the newest MaxBackups are kept.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	MaxAge     time.Duration
+	MaxBackups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// Write writes p to the current file, rotating it first if needed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	tooBig := f.MaxSize > 0 && f.size+int64(len(p)) > f.MaxSize
+	tooOld := f.MaxAge > 0 && time.Since(f.openedAt) > f.MaxAge
+	if (tooBig || tooOld) && f.size > 0 {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file for appending, creating it and its directory if
+// needed.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("[in logging.RotatingFile.open] failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("[in logging.RotatingFile.open] failed to open file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("[in logging.RotatingFile.open] failed to stat file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+
+	return nil
+}
+
+// rotate renames the current file with a timestamp suffix, opens a new one,
+// and removes backups beyond MaxBackups.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("[in logging.RotatingFile.rotate] failed to close file: %w", err)
+	}
+	f.file = nil
+
+	backup := f.Path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(f.Path, backup); err != nil {
+		return fmt.Errorf("[in logging.RotatingFile.rotate] failed to rename file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(f.Path + ".*")
+	if err != nil {
+		return fmt.Errorf("[in logging.RotatingFile.rotate] failed to list backups: %w", err)
+	}
+
+	// Timestamp suffixes sort chronologically, so the oldest come first.
+	sort.Strings(backups)
+	for len(backups) > f.MaxBackups {
+		if !strings.HasPrefix(backups[0], f.Path+".") {
+			break
+		}
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("[in logging.RotatingFile.rotate] failed to remove backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build !windows
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+
+	"github.com/jha-captech/blog/internal/config"
+)
+
+// dialSyslog connects to the configured syslog daemon.
+func dialSyslog(sink config.LogSink) (*syslog.Writer, error) {
+	w, err := syslog.Dial(sink.SyslogNetwork, sink.SyslogAddress, syslog.LOG_INFO|syslog.LOG_DAEMON, sink.SyslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("[in logging.dialSyslog] failed to connect to syslog: %w", err)
+	}
+	return w, nil
+}
+
+// dialSyslogWriter connects to the configured syslog daemon, returning the
+// connection as a plain writer.
+func dialSyslogWriter(sink config.LogSink) (io.WriteCloser, error) {
+	return dialSyslog(sink)
+}
+
+// dialSyslogHandler connects to the configured syslog daemon, returning a
+// handler sending records to it and the connection to close on shutdown.
+func dialSyslogHandler(sink config.LogSink, opts *slog.HandlerOptions) (slog.Handler, io.Closer, error) {
+	w, err := dialSyslog(sink)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newSyslogHandler(w, opts), w, nil
+}
+
+// syslogHandler is a slog.Handler that formats records as JSON and sends each
+// one to syslog with the priority matching its level.
+type syslogHandler struct {
+	w    *syslog.Writer
+	opts *slog.HandlerOptions
+
+	// wrap re-applies the attributes and groups added with WithAttrs and
+	// WithGroup to the JSON handler created for every record.
+	wrap func(slog.Handler) slog.Handler
+}
+
+// newSyslogHandler creates a new syslogHandler and returns a pointer to it.
+func newSyslogHandler(w *syslog.Writer, opts *slog.HandlerOptions) *syslogHandler {
+	return &syslogHandler{
+		w:    w,
+		opts: opts,
+		wrap: func(h slog.Handler) slog.Handler { return h },
+	}
+}
+
+func (h *syslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	if err := h.wrap(slog.NewJSONHandler(&buf, h.opts)).Handle(ctx, r); err != nil {
+		return err
+	}
+
+	msg := buf.String()
+	switch {
+	case r.Level >= slog.LevelError:
+		return h.w.Err(msg)
+	case r.Level >= slog.LevelWarn:
+		return h.w.Warning(msg)
+	case r.Level >= slog.LevelInfo:
+		return h.w.Info(msg)
+	default:
+		return h.w.Debug(msg)
+	}
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	wrap := h.wrap
+	return &syslogHandler{
+		w:    h.w,
+		opts: h.opts,
+		wrap: func(inner slog.Handler) slog.Handler { return wrap(inner).WithAttrs(attrs) },
+	}
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	wrap := h.wrap
+	return &syslogHandler{
+		w:    h.w,
+		opts: h.opts,
+		wrap: func(inner slog.Handler) slog.Handler { return wrap(inner).WithGroup(name) },
+	}
+}
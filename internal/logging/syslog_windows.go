@@ -0,0 +1,26 @@
+//go:build windows
+
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jha-captech/blog/internal/config"
+)
+
+// errSyslogUnsupported is returned when syslog output is configured on Windows,
+// which has no syslog daemon.
+var errSyslogUnsupported = errors.New("unsupported sink: syslog is not available on windows")
+
+// dialSyslogWriter reports that syslog output is not supported.
+func dialSyslogWriter(sink config.LogSink) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("[in logging.dialSyslogWriter] %w", errSyslogUnsupported)
+}
+
+// dialSyslogHandler reports that syslog output is not supported.
+func dialSyslogHandler(sink config.LogSink, opts *slog.HandlerOptions) (slog.Handler, io.Closer, error) {
+	return nil, nil, fmt.Errorf("[in logging.dialSyslogHandler] %w", errSyslogUnsupported)
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceHandler is a slog.Handler wrapper that adds the trace_id and span_id of
+// the span in the record's context, so logs can be joined with traces in the
+// observability backend. Records logged without a span are passed through
+// unchanged.
+type TraceHandler struct {
+	next slog.Handler
+}
+
+// NewTraceHandler wraps next in a TraceHandler and returns a pointer to it.
+func NewTraceHandler(next slog.Handler) *TraceHandler {
+	return &TraceHandler{next: next}
+}
+
+func (h *TraceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r = r.Clone()
+		r.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TraceHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return &TraceHandler{next: h.next.WithGroup(name)}
+}
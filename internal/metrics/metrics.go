@@ -0,0 +1,265 @@
+// Package metrics records counters, gauges and histograms in memory and
+// exposes them in the Prometheus text exposition format, so they can be
+// scraped without pulling in the Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the histogram buckets
+// used for request and query durations.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds a set of metrics and writes them out when scraped. Metrics
+// must be created through the registry, and are safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*metric
+}
+
+// NewRegistry creates a new, empty Registry and returns a pointer to it.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter is a value that only goes up, such as a number of requests, split
+// into series by label values. A nil Counter discards updates.
+type Counter struct{ m *metric }
+
+// Gauge is a value that goes up and down, such as a number of requests in
+// flight, split into series by label values. A nil Gauge discards updates.
+type Gauge struct{ m *metric }
+
+// Histogram counts observations, such as request durations, into buckets,
+// split into series by label values. A nil Histogram discards observations.
+type Histogram struct{ m *metric }
+
+// Counter registers a counter with the provided name, help text and label
+// names, and returns a pointer to it.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{m: r.register(name, help, "counter", nil, labels)}
+}
+
+// Gauge registers a gauge with the provided name, help text and label names,
+// and returns a pointer to it.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{m: r.register(name, help, "gauge", nil, labels)}
+}
+
+// Histogram registers a histogram with the provided name, help text, bucket
+// upper bounds in increasing order and label names, and returns a pointer to
+// it.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{m: r.register(name, help, "histogram", buckets, labels)}
+}
+
+// Add adds delta, which must not be negative, to the series with the provided
+// label values.
+func (c *Counter) Add(delta float64, values ...string) {
+	if c == nil {
+		return
+	}
+	c.m.update(values, func(s *series) { s.value += delta })
+}
+
+// Inc adds one to the series with the provided label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the series with the provided label values.
+func (g *Gauge) Add(delta float64, values ...string) {
+	if g == nil {
+		return
+	}
+	g.m.update(values, func(s *series) { s.value += delta })
+}
+
+// Observe records v in the series with the provided label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	if h == nil {
+		return
+	}
+	h.m.update(values, func(s *series) {
+		s.value += v
+		s.count++
+		for i, bound := range h.m.buckets {
+			if v <= bound {
+				s.buckets[i]++
+			}
+		}
+	})
+}
+
+// metric holds the series of a single metric.
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	buckets []float64
+	labels  []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series holds the value of a metric for one set of label values. Histograms
+// keep the sum of observations in value and cumulative bucket counts.
+type series struct {
+	values  []string
+	value   float64
+	count   uint64
+	buckets []uint64
+}
+
+func (r *Registry) register(name, help, kind string, buckets []float64, labels []string) *metric {
+	m := &metric{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		buckets: buckets,
+		labels:  labels,
+		series:  make(map[string]*series),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// update applies fn to the series with the provided label values, creating it
+// if needed. Panics if the number of values does not match the labels, as
+// that is a programming error.
+func (m *metric) update(values []string, fn func(s *series)) {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", m.name, len(m.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: slices.Clone(values)}
+		if m.buckets != nil {
+			s.buckets = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	fn(s)
+}
+
+// WriteTo writes every metric to w in the Prometheus text exposition format.
+// Series are sorted by label values so the output is stable.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	err := bw.Flush()
+
+	return cw.n, err
+}
+
+func (m *metric) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+
+	for _, key := range slices.Sorted(maps.Keys(m.series)) {
+		s := m.series[key]
+		if m.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", m.name, m.labelSet(s.values, "", ""), formatFloat(s.value))
+			continue
+		}
+
+		for i, bound := range m.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, m.labelSet(s.values, "le", formatFloat(bound)), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", m.name, m.labelSet(s.values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", m.name, m.labelSet(s.values, "", ""), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", m.name, m.labelSet(s.values, "", ""), s.count)
+	}
+}
+
+// labelSet formats the label values of a series, followed by an extra label
+// when extraName is set, e.g. {route="GET /",le="0.5"}.
+func (m *metric) labelSet(values []string, extraName, extraValue string) string {
+	if len(values) == 0 && extraName == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(m.labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabel(value))
+		sb.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(values) > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(extraName)
+		sb.WriteString(`="`)
+		sb.WriteString(extraValue)
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
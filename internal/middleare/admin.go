@@ -0,0 +1,38 @@
+package middleare
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+)
+
+// RequireAdmin is a middleware that only lets through requests from the users
+// with the provided ids. It must wrap handlers behind Authenticate, which
+// stores the user id it checks. Other users are rejected with a 403 and
+// reported as security events.
+func RequireAdmin(adminIDs []uint, securityEvents *events.Emitter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := auth.UserIDFromContext(r.Context())
+			if !ok || !slices.Contains(adminIDs, userID) {
+				securityEvents.Emit(r.Context(), events.Event{
+					Type:     events.TypePermissionDenied,
+					Severity: events.SeverityWarning,
+					Message:  "user tried to use an admin route",
+					Attrs: map[string]string{
+						"method": r.Method,
+						"path":   r.URL.Path,
+					},
+				})
+
+				problem.Error(w, r, http.StatusForbidden, "Only administrators can use this route")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
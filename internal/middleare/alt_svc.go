@@ -0,0 +1,26 @@
+package middleare
+
+import (
+	"net/http"
+)
+
+// quicHeaderSetter represents a type capable of setting the headers that
+// advertise an HTTP/3 endpoint, such as an http3.Server.
+type quicHeaderSetter interface {
+	SetQUICHeaders(hdr http.Header) error
+}
+
+// AltSvc is a middleware that advertises the HTTP/3 server on responses sent
+// over HTTP/1.1 and HTTP/2, so clients can switch to it for later requests.
+func AltSvc(server quicHeaderSetter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor < 3 {
+				// The header is only missing while the server has no listener,
+				// in which case there is nothing to advertise.
+				_ = server.SetQUICHeaders(w.Header())
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
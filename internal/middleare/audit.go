@@ -0,0 +1,35 @@
+package middleare
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jha-captech/blog/internal/security/events"
+)
+
+// Audit is a middleware that emits an admin action security event for every
+// request it wraps, once the request has been handled, recording the route
+// and the response status.
+func Audit(securityEvents *events.Emitter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped := &wrappedWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			securityEvents.Emit(r.Context(), events.Event{
+				Type:     events.TypeAdminAction,
+				Severity: events.SeverityInfo,
+				Message:  "admin action",
+				Attrs: map[string]string{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"status": strconv.Itoa(wrapped.statusCode),
+				},
+			})
+		})
+	}
+}
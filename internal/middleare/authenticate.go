@@ -0,0 +1,54 @@
+package middleare
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+)
+
+// Authenticate is a middleware that only lets through requests carrying a
+// valid bearer token in the Authorization header, storing the id of the user
+// it was issued to in the request context, where auth.UserIDFromContext
+// retrieves it. Other requests are rejected with a 401, and invalid tokens are
+// reported as security events.
+func Authenticate(logger *slog.Logger, tokens *auth.Tokens, securityEvents *events.Emitter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				problem.Error(w, r, http.StatusUnauthorized, "A bearer token is required")
+				return
+			}
+
+			userID, err := tokens.Verify(token)
+			if err != nil {
+				logger.WarnContext(
+					r.Context(),
+					"rejected bearer token",
+					slog.String("error", err.Error()),
+				)
+
+				securityEvents.Emit(r.Context(), events.Event{
+					Type:     events.TypeTokenRejected,
+					Severity: events.SeverityWarning,
+					Message:  "rejected bearer token",
+					Attrs: map[string]string{
+						"error": err.Error(),
+						"path":  r.URL.Path,
+					},
+				})
+
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				problem.Error(w, r, http.StatusUnauthorized, "The bearer token is invalid or has expired")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), userID)))
+		})
+	}
+}
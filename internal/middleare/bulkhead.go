@@ -0,0 +1,69 @@
+package middleare
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// Bulkheads caps the number of in-flight requests for groups of routes, so a
+// burst of requests to one expensive endpoint cannot use up the whole server.
+type Bulkheads struct {
+	Logger *slog.Logger
+	// Limits holds the maximum number of concurrent requests per route group.
+	// Groups without a limit are not restricted.
+	Limits map[string]int
+	// QueueTimeout is how long a request waits for a free slot before it is
+	// shed with a 503.
+	QueueTimeout time.Duration
+}
+
+// Group returns a middleware enforcing the named group's limit. Every route
+// wrapped with the returned middleware shares the same slots, so it must be
+// called once per group.
+func (b Bulkheads) Group(name string) Middleware {
+	limit := b.Limits[name]
+	if limit <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				// All slots are busy, wait briefly for one to free up.
+				timer := time.NewTimer(b.QueueTimeout)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+				case <-timer.C:
+					b.Logger.WarnContext(
+						r.Context(),
+						"bulkhead full, shedding request",
+						slog.String("group", name),
+						slog.Int("limit", limit),
+						slog.String("method", r.Method),
+						slog.String("path", r.URL.Path),
+					)
+
+					w.Header().Set("Retry-After", "1")
+					problem.Error(w, r, http.StatusServiceUnavailable, "The server is overloaded, retry shortly")
+					return
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
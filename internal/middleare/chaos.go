@@ -0,0 +1,32 @@
+package middleare
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/chaos"
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// Chaos is a middleware that injects latency and errors into requests using
+// the provided injector. Failed requests are answered with a 503 without
+// reaching the wrapped handler.
+func Chaos(logger *slog.Logger, injector *chaos.Injector) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := injector.Inject(r.Context()); err != nil {
+				logger.WarnContext(
+					r.Context(),
+					"chaos injected request failure",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				problem.Error(w, r, http.StatusServiceUnavailable, "")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
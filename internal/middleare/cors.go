@@ -0,0 +1,119 @@
+package middleare
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// CORS lets browser clients served from other origins call the API. Preflight
+// requests are answered for the registered routes only, and other requests
+// from allowed origins are given the headers that let the browser read their
+// response. Requests from other origins are served without them, so the
+// browser keeps their responses from the page.
+type CORS struct {
+	// AllowedOrigins are the origins allowed to call the API, such as
+	// "https://blog.example.com", or "*" for any origin.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are the methods and request headers
+	// cross-origin requests may use.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers the browser lets pages read,
+	// besides the always readable ones such as Content-Type.
+	ExposedHeaders []string
+	// AllowCredentials lets requests carry cookies. It is ignored when any
+	// origin is allowed, as browsers refuse it.
+	AllowCredentials bool
+	// MaxAge is how long browsers reuse a preflight response.
+	MaxAge time.Duration
+}
+
+// Handler returns a middleware applying the CORS policy to the routes of the
+// provided router. It must wrap the router itself, as preflight requests are
+// answered before routing, for every registered route.
+func (c CORS) Handler(router router) Middleware {
+	anyOrigin := slices.Contains(c.AllowedOrigins, "*")
+	allowedHeaders := make([]string, len(c.AllowedHeaders))
+	for i, header := range c.AllowedHeaders {
+		allowedHeaders[i] = http.CanonicalHeaderKey(header)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			allowed := anyOrigin || slices.Contains(c.AllowedOrigins, origin)
+
+			requestedMethod := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || requestedMethod == "" {
+				if allowed {
+					c.allowOrigin(w, origin, anyOrigin)
+					if len(c.ExposedHeaders) > 0 {
+						w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Preflight request
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			if !allowed {
+				problem.Error(w, r, http.StatusForbidden, "Cross-origin requests from "+origin+" are not allowed")
+				return
+			}
+
+			// Only methods a route is registered for are allowed, by looking
+			// the route up as if the request had been made.
+			probe := r.Clone(r.Context())
+			probe.Method = requestedMethod
+			if _, pattern := router.Handler(probe); pattern == "" || !slices.Contains(c.AllowedMethods, requestedMethod) {
+				problem.Error(w, r, http.StatusForbidden, "Cross-origin "+requestedMethod+" requests to this path are not allowed")
+				return
+			}
+
+			for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+				header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+				if header != "" && !slices.Contains(allowedHeaders, header) {
+					problem.Error(w, r, http.StatusForbidden, "Cross-origin requests may not send the "+header+" header")
+					return
+				}
+			}
+
+			c.allowOrigin(w, origin, anyOrigin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+			if len(c.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+			}
+			if c.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowOrigin sets the headers letting origin read the response.
+func (c CORS) allowOrigin(w http.ResponseWriter, origin string, anyOrigin bool) {
+	if anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
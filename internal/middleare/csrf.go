@@ -0,0 +1,133 @@
+package middleare
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/security/events"
+)
+
+// CSRFHeader is the request header browser clients echo the CSRF token in.
+const CSRFHeader = "X-CSRF-Token"
+
+// CSRF protects the routes browser clients change state with against cross
+// site request forgery, using double-submit cookies. Browser clients fetch a
+// token from GET /api/csrf, which also sets it as a cookie, and send it back in
+// the X-CSRF-Token header of every unsafe request, including logging in and
+// signing up. A forged request from another site carries the cookie but cannot
+// read it to set the header. Clients on another origin must send credentials
+// with their requests, which CORS must allow, for the cookie to be set and
+// sent back, and clients on another site also need SameSite set to none.
+//
+// Requests that no browser could have been tricked into sending are exempt:
+// those with a bearer token, as browsers never add an Authorization header to
+// cross-site requests on their own, and those with neither an Origin nor a
+// Sec-Fetch-Site header, which browsers send with every cross-site unsafe
+// request. Clients other than browsers can therefore skip fetching a token.
+type CSRF struct {
+	Logger *slog.Logger
+	// Secure only sends the cookie over HTTPS and gives it the __Host- prefix,
+	// which stops subdomains from overwriting it. Only disable it for local
+	// development over plain HTTP.
+	Secure bool
+	// SameSite is the SameSite attribute of the cookie. Strict only sends it
+	// from pages on the same site as the API, and None, which requires Secure,
+	// lets clients on other sites send it.
+	SameSite http.SameSite
+	// Events receives a security event for every rejected request.
+	Events *events.Emitter
+}
+
+// cookieName returns the name of the cookie holding the token.
+func (c CSRF) cookieName() string {
+	if c.Secure {
+		return "__Host-csrf"
+	}
+	return "csrf"
+}
+
+// Issue generates a new token, sets it as a cookie on the response and returns
+// it so it can also be sent in the response body.
+func (c CSRF) Issue(w http.ResponseWriter) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("[in middleare.CSRF.Issue] failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.cookieName(),
+		Value:    token,
+		Path:     "/",
+		Secure:   c.Secure,
+		HttpOnly: true,
+		SameSite: c.SameSite,
+	})
+
+	return token, nil
+}
+
+// Protect returns a middleware rejecting unsafe requests whose X-CSRF-Token
+// header does not match their CSRF cookie with a 403.
+func (c CSRF) Protect() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if hasBearerToken(r) || !fromBrowser(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cookie, err := r.Cookie(c.cookieName())
+			header := r.Header.Get(CSRFHeader)
+			if err != nil || header == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+				c.Logger.WarnContext(
+					r.Context(),
+					"rejected request without a valid csrf token",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				c.Events.Emit(r.Context(), events.Event{
+					Type:     events.TypeCSRFRejected,
+					Severity: events.SeverityWarning,
+					Message:  "rejected request without a valid csrf token",
+					Attrs: map[string]string{
+						"method": r.Method,
+						"path":   r.URL.Path,
+					},
+				})
+
+				problem.Error(w, r, http.StatusForbidden, "A valid "+CSRFHeader+" header is required, fetch one from /api/csrf")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasBearerToken reports whether r has an Authorization header using the
+// Bearer scheme, whose token is verified by Authenticate.
+func hasBearerToken(r *http.Request) bool {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	return strings.EqualFold(scheme, "Bearer") && token != ""
+}
+
+// fromBrowser reports whether r may have been sent by a browser. Browsers send
+// an Origin header with every cross-site unsafe request, and newer ones a
+// Sec-Fetch-Site header with every request, neither of which pages can remove.
+func fromBrowser(r *http.Request) bool {
+	return r.Header.Get("Origin") != "" || r.Header.Get("Sec-Fetch-Site") != ""
+}
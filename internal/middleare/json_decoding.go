@@ -0,0 +1,33 @@
+package middleare
+
+import (
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/validation"
+)
+
+// JSONDecoding sets how strictly each route group decodes JSON request bodies.
+// Strict groups reject fields the request type does not have, so mistakes in
+// internal clients are caught early, while lenient ones ignore them so older
+// public clients keep working.
+type JSONDecoding struct {
+	// Strict reports whether each group rejects unknown fields. Groups missing
+	// from it use Default.
+	Strict  map[string]bool
+	Default bool
+}
+
+// Group returns a middleware applying the named group's strictness to the
+// requests it wraps.
+func (d JSONDecoding) Group(name string) Middleware {
+	strict, ok := d.Strict[name]
+	if !ok {
+		strict = d.Default
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(validation.WithStrictDecoding(r.Context(), strict)))
+		})
+	}
+}
@@ -1,14 +1,21 @@
 package middleare
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/jha-captech/blog/internal/realip"
 )
 
 type wrappedWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 }
 
 func (w *wrappedWriter) WriteHeader(statusCode int) {
@@ -16,9 +23,50 @@ func (w *wrappedWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
 }
 
+func (w *wrappedWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// LatencyBudgets holds the expected maximum duration of requests, keyed by
+// route pattern, e.g. "GET /api/users/{id}". Routes without a budget use
+// Default, and a zero budget disables slow request flagging.
+type LatencyBudgets struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// For returns the latency budget for the provided route pattern.
+func (b LatencyBudgets) For(pattern string) time.Duration {
+	if budget, ok := b.Routes[pattern]; ok {
+		return budget
+	}
+	return b.Default
+}
+
+// Supported access log formats.
+const (
+	// AccessLogJSON logs requests as structured records through the logger.
+	AccessLogJSON = "json"
+	// AccessLogCombined writes requests in the Apache Combined Log Format.
+	AccessLogCombined = "clf"
+)
+
+// LoggerOptions holds the settings of the Logger middleware.
+type LoggerOptions struct {
+	// Budgets are used to flag slow requests.
+	Budgets LatencyBudgets
+	// Format is the access log format, AccessLogJSON or AccessLogCombined.
+	Format string
+	// Output is where Combined Log Format lines are written.
+	Output io.Writer
+}
+
 // Logger is a middleware that logs the request method, path, duration, and
-// status code.
-func Logger(logger *slog.Logger) Middleware {
+// status code. Requests that take longer than their route's latency budget
+// are flagged with slow=true.
+func Logger(logger *slog.Logger, options LoggerOptions) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -30,14 +78,94 @@ func Logger(logger *slog.Logger) Middleware {
 
 			next.ServeHTTP(wrapped, r)
 
-			logger.InfoContext(
-				r.Context(),
-				"request completed",
+			duration := time.Since(start)
+
+			// The mux sets the matched route pattern on the request, so the
+			// budget can only be looked up once the request has been served.
+			budget := options.Budgets.For(r.Pattern)
+			slow := budget > 0 && duration > budget
+
+			if options.Format == AccessLogCombined {
+				_, _ = io.WriteString(options.Output, combinedLogLine(r, wrapped, start))
+
+				// Combined Log Format has no room for the slow flag.
+				if slow {
+					logger.WarnContext(
+						r.Context(),
+						"slow request",
+						slog.String("route", r.Pattern),
+						slog.String("duration", duration.String()),
+						slog.String("budget", budget.String()),
+					)
+				}
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("client_ip", clientHost(r)),
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
-				slog.String("duration", time.Since(start).String()),
+				slog.String("route", r.Pattern),
+				slog.String("duration", duration.String()),
 				slog.Int("status", wrapped.statusCode),
-			)
+			}
+
+			if slow {
+				attrs = append(attrs, slog.Bool("slow", true), slog.String("budget", budget.String()))
+			}
+
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request completed", attrs...)
 		})
 	}
 }
+
+// combinedLogLine formats a request in the Apache Combined Log Format:
+//
+//	host ident user [time] "request line" status bytes "referer" "user agent"
+func combinedLogLine(r *http.Request, w *wrappedWriter, start time.Time) string {
+	host := clientHost(r)
+
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+
+	size := "-"
+	if w.bytes > 0 {
+		size = strconv.Itoa(w.bytes)
+	}
+
+	return fmt.Sprintf(
+		"%s - %s [%s] %q %d %s %q %q\n",
+		host,
+		user,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.RequestURI+" "+r.Proto,
+		w.statusCode,
+		size,
+		valueOrDash(r.Referer()),
+		valueOrDash(r.UserAgent()),
+	)
+}
+
+// clientHost returns the client address resolved by the RealIP middleware,
+// falling back to the address of the peer that sent the request.
+func clientHost(r *http.Request) string {
+	if addr, ok := realip.FromContext(r.Context()); ok && addr.IsValid() {
+		return addr.String()
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// valueOrDash returns v, or "-" when v is empty.
+func valueOrDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+package middleare
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// memoEntry is a response shared by the requests that arrive while it is being
+// produced and until it expires.
+type memoEntry struct {
+	done    chan struct{}
+	rec     *recordingWriter
+	expires time.Time
+}
+
+// Memoize is a middleware for endpoints that are polled frequently and are
+// expensive to serve, such as health probes and metrics scrapes. Concurrent
+// GET and HEAD requests for the same URL are coalesced into a single call to
+// the wrapped handler, and its response is reused for ttl.
+func Memoize(ttl time.Duration) Middleware {
+	var (
+		mu      sync.Mutex
+		entries = make(map[string]*memoEntry)
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.Method + " " + r.URL.RequestURI()
+
+			mu.Lock()
+			entry, ok := entries[key]
+			if !ok || entry.expired() {
+				entry = &memoEntry{
+					done: make(chan struct{}),
+					rec:  &recordingWriter{header: make(http.Header), statusCode: http.StatusOK},
+				}
+				entries[key] = entry
+				mu.Unlock()
+
+				func() {
+					// Waiting requests are released even if the handler panics,
+					// in which case the partial response expires immediately.
+					defer close(entry.done)
+					entry.expires = time.Now()
+
+					// The response is shared, so it must not be cut short when
+					// the client that triggered it goes away.
+					next.ServeHTTP(entry.rec, r.WithContext(context.WithoutCancel(r.Context())))
+					entry.expires = time.Now().Add(ttl)
+				}()
+			} else {
+				mu.Unlock()
+
+				select {
+				case <-entry.done:
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			entry.rec.writeTo(w)
+		})
+	}
+}
+
+// expired reports whether the entry's response is complete and past its ttl.
+// Must be called with the Memoize lock held.
+func (e *memoEntry) expired() bool {
+	select {
+	case <-e.done:
+		return time.Now().After(e.expires)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,67 @@
+package middleare
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jha-captech/blog/internal/metrics"
+)
+
+// unmatchedRoute is the route label of requests no route matched, which keeps
+// the number of series bounded however many paths are requested.
+const unmatchedRoute = "unmatched"
+
+// router represents a type capable of finding the route pattern a request
+// matches, such as *http.ServeMux.
+type router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// Metrics is a middleware that records the number, duration and status of
+// requests, and the requests in flight, by route pattern in the provided
+// registry. The route is looked up in router before the request is handled, so
+// requests in flight can be attributed to it.
+func Metrics(registry *metrics.Registry, router router) Middleware {
+	requests := registry.Counter(
+		"http_requests_total",
+		"Number of HTTP requests handled, by route and status code.",
+		"route", "status",
+	)
+	durations := registry.Histogram(
+		"http_request_duration_seconds",
+		"Duration of HTTP requests, by route and status code.",
+		metrics.DefaultBuckets,
+		"route", "status",
+	)
+	inFlight := registry.Gauge(
+		"http_requests_in_flight",
+		"Number of HTTP requests being handled, by route.",
+		"route",
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			_, route := router.Handler(r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			inFlight.Add(1, route)
+			defer inFlight.Add(-1, route)
+
+			wrapped := &wrappedWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			status := strconv.Itoa(wrapped.statusCode)
+			requests.Inc(route, status)
+			durations.Observe(time.Since(start).Seconds(), route, status)
+		})
+	}
+}
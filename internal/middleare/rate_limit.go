@@ -0,0 +1,103 @@
+package middleare
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/problem"
+	"github.com/jha-captech/blog/internal/ratelimit"
+	"github.com/jha-captech/blog/internal/security/events"
+)
+
+// RateLimits limits how many requests each client can make to groups of
+// routes, so a single client cannot crowd out the others. Clients are told
+// when to retry with a 429.
+type RateLimits struct {
+	Logger *slog.Logger
+	// Store tracks the requests made by each client.
+	Store ratelimit.Store
+	// Limits holds the rate limit per route group. Groups without a limit are
+	// not restricted.
+	Limits map[string]ratelimit.Limit
+	// Tokens identifies logged in users by their bearer token, so users
+	// sharing an address get their own limit. Requests without a valid token
+	// are limited by client address.
+	Tokens *auth.Tokens
+	// Events records clients that were rate limited.
+	Events *events.Emitter
+}
+
+// Group returns a middleware enforcing the named group's limit.
+func (rl RateLimits) Group(name string) Middleware {
+	limit, ok := rl.Limits[name]
+	if !ok {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			client := rl.client(r)
+
+			allowed, retryAfter, err := rl.Store.Take(ctx, name+":"+client, limit)
+			if err != nil {
+				// Failing open keeps the API up when the store is down.
+				rl.Logger.ErrorContext(
+					ctx,
+					"failed to check rate limit",
+					slog.String("group", name),
+					slog.String("error", err.Error()),
+				)
+			}
+
+			if err == nil && !allowed {
+				rl.Logger.WarnContext(
+					ctx,
+					"rate limit exceeded",
+					slog.String("group", name),
+					slog.String("client", client),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				rl.Events.Emit(ctx, events.Event{
+					Type:     events.TypeRateLimited,
+					Severity: events.SeverityInfo,
+					Message:  "rate limit exceeded",
+					Attrs: map[string]string{
+						"group":  name,
+						"client": client,
+						"path":   r.URL.Path,
+					},
+				})
+
+				seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				problem.Error(w, r, http.StatusTooManyRequests, "Too many requests, retry after the time given in Retry-After")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// client returns the key requests are counted under: the logged in user when
+// the request carries a valid bearer token, and its client address otherwise.
+func (rl RateLimits) client(r *http.Request) string {
+	if rl.Tokens != nil {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if strings.EqualFold(scheme, "Bearer") && token != "" {
+			if userID, err := rl.Tokens.Verify(token); err == nil {
+				return "user:" + strconv.FormatUint(uint64(userID), 10)
+			}
+		}
+	}
+	return "ip:" + clientHost(r)
+}
@@ -0,0 +1,18 @@
+package middleare
+
+import (
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/realip"
+)
+
+// RealIP is a middleware that resolves the client address of each request and
+// stores it in the request context, where realip.FromContext retrieves it.
+func RealIP(resolver *realip.Resolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := realip.NewContext(r.Context(), resolver.ClientIP(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middleare
+
+import (
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/requestid"
+)
+
+// RequestID is a middleware that identifies each request, reusing a valid
+// X-Request-ID sent by the client or a proxy and generating one otherwise. The
+// id is sent back in the response and stored in the request context, where
+// requestid.FromContext retrieves it and logging.RequestIDHandler adds it to
+// log records.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+
+			w.Header().Set(requestid.Header, id)
+			ctx := requestid.NewContext(r.Context(), id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
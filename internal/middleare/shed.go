@@ -0,0 +1,35 @@
+package middleare
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/jha-captech/blog/internal/overload"
+	"github.com/jha-captech/blog/internal/problem"
+)
+
+// Shed is a middleware that rejects requests with a 503 when the provided
+// shedder reports the server is too loaded to serve requests of the given
+// priority.
+func Shed(logger *slog.Logger, shedder *overload.Shedder, priority overload.Priority) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !shedder.Admit(priority) {
+				logger.WarnContext(
+					r.Context(),
+					"server overloaded, shedding request",
+					slog.String("priority", priority.String()),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+				)
+
+				w.Header().Set("Retry-After", "1")
+				problem.Error(w, r, http.StatusServiceUnavailable, "The server is overloaded, retry shortly")
+				return
+			}
+			defer shedder.Done()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
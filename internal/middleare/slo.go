@@ -0,0 +1,33 @@
+package middleare
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jha-captech/blog/internal/slo"
+)
+
+// SLO is a middleware that records the outcome of every request in the
+// provided tracker. Requests are counted as errors when they fail with a 5xx
+// status code, and as slow when they exceed their route's latency budget.
+func SLO(tracker *slo.Tracker, budgets LatencyBudgets) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			wrapped := &wrappedWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			budget := budgets.For(r.Pattern)
+			tracker.Record(
+				r.Pattern,
+				wrapped.statusCode >= http.StatusInternalServerError,
+				budget > 0 && time.Since(start) > budget,
+			)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package middleare
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// cachedResponse is a successful response captured by StaticCache.
+type cachedResponse struct {
+	header http.Header
+	body   []byte
+	etag   string
+}
+
+// StaticCache is a middleware for handlers that serve content which does not
+// change while the process runs, such as embedded swagger assets. The first
+// successful response for each path is kept in memory and served from there
+// afterwards, with a content hash ETag and Cache-Control headers so browsers
+// and CDNs can cache it and revalidate with If-None-Match.
+func StaticCache(maxAge time.Duration) Middleware {
+	var (
+		mu    sync.RWMutex
+		cache = make(map[string]*cachedResponse)
+	)
+
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mu.RLock()
+			cached, ok := cache[r.URL.Path]
+			mu.RUnlock()
+
+			if !ok {
+				rec := &recordingWriter{header: make(http.Header), statusCode: http.StatusOK}
+				next.ServeHTTP(rec, r)
+
+				// Only successful responses are cached, anything else is passed
+				// through as is.
+				if rec.statusCode != http.StatusOK {
+					rec.writeTo(w)
+					return
+				}
+
+				sum := sha256.Sum256(rec.body.Bytes())
+				cached = &cachedResponse{
+					header: rec.header,
+					body:   rec.body.Bytes(),
+					etag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+				}
+
+				mu.Lock()
+				cache[r.URL.Path] = cached
+				mu.Unlock()
+			}
+
+			for key, values := range cached.header {
+				w.Header()[key] = values
+			}
+			w.Header().Set("ETag", cached.etag)
+			w.Header().Set("Cache-Control", cacheControl)
+
+			if r.Header.Get("If-None-Match") == cached.etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(cached.body)
+			}
+		})
+	}
+}
+
+// recordingWriter is an http.ResponseWriter that captures a response in
+// memory.
+type recordingWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *recordingWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// writeTo replays the captured response on w.
+func (w *recordingWriter) writeTo(rw http.ResponseWriter) {
+	for key, values := range w.header {
+		rw.Header()[key] = values
+	}
+	rw.WriteHeader(w.statusCode)
+	_, _ = rw.Write(w.body.Bytes())
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Audiences an announcement can target.
+const (
+	AudienceAll           = "all"
+	AudienceAnonymous     = "anonymous"
+	AudienceAuthenticated = "authenticated"
+)
+
+type Announcement struct {
+	ID        uint
+	Message   string
+	Audience  string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Canary is a decoy user that no one should ever use. Logging in as one, or
+// reading it, means credentials or data have leaked.
+type Canary struct {
+	UserID    uint
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
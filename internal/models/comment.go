@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Comment struct {
+	ID        uint
+	PostID    uint
+	AuthorID  uint
+	Message   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CSPReport is a Content Security Policy violation reported by a browser.
+type CSPReport struct {
+	ID                 uint
+	DocumentURI        string
+	BlockedURI         string
+	EffectiveDirective string
+	Disposition        string
+	SourceFile         string
+	LineNumber         int
+	ReceivedAt         time.Time
+}
+
+// CSPReportSummary counts the violations of a directive by a blocked URI.
+type CSPReportSummary struct {
+	EffectiveDirective string
+	BlockedURI         string
+	Count              int
+	FirstSeen          time.Time
+	LastSeen           time.Time
+}
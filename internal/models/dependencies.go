@@ -0,0 +1,34 @@
+package models
+
+// Optional features reported in Dependencies.Features.
+const (
+	// FeatureFullTextSearch is the database's built-in full-text search.
+	FeatureFullTextSearch = "full_text_search"
+	// FeatureTrigramSearch is the Postgres pg_trgm extension, used for fuzzy
+	// and substring matching.
+	FeatureTrigramSearch = "pg_trgm"
+	// FeatureVectorSearch is the Postgres pgvector extension, used to find
+	// related posts by embedding distance.
+	FeatureVectorSearch = "vector"
+)
+
+// Dependencies describes the versions of the services the application depends
+// on, and which optional features they provide.
+type Dependencies struct {
+	Database DatabaseInfo
+	// Features reports, by feature name, whether the feature is available.
+	Features map[string]bool
+}
+
+// DatabaseInfo describes the database server.
+type DatabaseInfo struct {
+	Driver     string
+	Version    string
+	Extensions []Extension
+}
+
+// Extension is a database extension installed in the database.
+type Extension struct {
+	Name    string
+	Version string
+}
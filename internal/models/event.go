@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Supported analytics event types.
+const (
+	EventPageView    = "page_view"
+	EventScrollDepth = "scroll_depth"
+)
+
+type Event struct {
+	ID         uint
+	Type       string
+	Path       string
+	SessionID  string
+	Value      *float64
+	ClientIP   string
+	UserAgent  string
+	OccurredAt time.Time
+	ReceivedAt time.Time
+}
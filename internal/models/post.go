@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type Post struct {
+	ID        uint
+	AuthorID  uint
+	Title     string
+	Body      string
+	Summary   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Settings struct {
+	Title       string
+	Description string
+	Theme       map[string]string
+	SocialLinks map[string]string
+	UpdatedAt   time.Time
+}
@@ -1,8 +1,21 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       uint
-	Name     string
-	Email    string
-	Password string
+	ID           uint
+	Name         string
+	Email        string
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// UserChange is a change made to a user. Changes are numbered by Seq in the
+// order they were made. User is only set for users that are not Deleted.
+type UserChange struct {
+	Seq     uint64
+	UserID  uint
+	Deleted bool
+	User    User
 }
@@ -0,0 +1,150 @@
+// Package overload detects when the server is overloaded and decides which
+// requests to shed, dropping low priority traffic first.
+package overload
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Priority is the importance of a request when the server is overloaded.
+type Priority int
+
+// Request priorities, from first to last shed.
+const (
+	// PriorityLow is for expensive requests that can be retried later, such
+	// as lists and searches.
+	PriorityLow Priority = iota
+	// PriorityNormal is for requests without a specific priority.
+	PriorityNormal
+	// PriorityHigh is for requests that are never shed, such as
+	// authentication and single reads.
+	PriorityHigh
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+// Load levels at which each priority starts being shed. The load is the
+// highest of the in-flight requests and the scheduler lag, relative to their
+// limits.
+const (
+	shedLowAt    = 0.75
+	shedNormalAt = 1.0
+)
+
+// Limits holds the levels at which the server is considered fully loaded. A
+// zero limit disables that signal.
+type Limits struct {
+	// MaxInFlight is the number of requests being served at once.
+	MaxInFlight int
+	// MaxLag is how late the Go scheduler runs a goroutine that is ready,
+	// which grows as the CPU becomes saturated.
+	MaxLag time.Duration
+}
+
+// Stats holds the current load and the number of requests shed since the
+// process started.
+type Stats struct {
+	InFlight int64            `json:"in_flight"`
+	Lag      time.Duration    `json:"lag_ns"`
+	Load     float64          `json:"load"`
+	Shed     map[string]int64 `json:"shed"`
+}
+
+// Shedder tracks the server's load and decides whether requests are admitted.
+type Shedder struct {
+	limits Limits
+
+	inFlight atomic.Int64
+	lag      atomic.Int64
+	shed     [PriorityHigh + 1]atomic.Int64
+}
+
+// NewShedder creates a new Shedder and returns a pointer to it.
+func NewShedder(limits Limits) *Shedder {
+	return &Shedder{limits: limits}
+}
+
+// Run measures the scheduler lag once per interval until ctx is done. The lag
+// stays at zero if Run is not called.
+func (s *Shedder) Run(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		expected := time.Now().Add(interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-timer.C:
+			// The timer fires on time, any delay is how long the goroutine
+			// waited to be scheduled. It is smoothed to ignore single spikes.
+			lag := max(now.Sub(expected), 0)
+			previous := time.Duration(s.lag.Load())
+			s.lag.Store(int64(previous + (lag-previous)/4))
+		}
+
+		timer.Reset(interval)
+	}
+}
+
+// Admit reports whether a request with the provided priority may be served.
+// Every admitted request must be followed by a call to Done.
+func (s *Shedder) Admit(priority Priority) bool {
+	load := s.load(s.inFlight.Load() + 1)
+
+	if (priority == PriorityLow && load >= shedLowAt) || (priority == PriorityNormal && load >= shedNormalAt) {
+		s.shed[priority].Add(1)
+		return false
+	}
+
+	s.inFlight.Add(1)
+	return true
+}
+
+// Done marks an admitted request as finished.
+func (s *Shedder) Done() {
+	s.inFlight.Add(-1)
+}
+
+// Stats returns the current load and shed counts.
+func (s *Shedder) Stats() Stats {
+	inFlight := s.inFlight.Load()
+
+	stats := Stats{
+		InFlight: inFlight,
+		Lag:      time.Duration(s.lag.Load()),
+		Load:     s.load(inFlight),
+		Shed:     make(map[string]int64, len(s.shed)),
+	}
+	for priority := range s.shed {
+		stats.Shed[Priority(priority).String()] = s.shed[priority].Load()
+	}
+
+	return stats
+}
+
+// load returns the server's load with the provided number of requests in
+// flight, where 1 means fully loaded.
+func (s *Shedder) load(inFlight int64) float64 {
+	var load float64
+	if s.limits.MaxInFlight > 0 {
+		load = float64(inFlight) / float64(s.limits.MaxInFlight)
+	}
+	if s.limits.MaxLag > 0 {
+		load = max(load, float64(s.lag.Load())/float64(s.limits.MaxLag))
+	}
+	return load
+}
@@ -0,0 +1,69 @@
+// Package password hashes user passwords for storage and checks passwords
+// against those hashes.
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxLength is the longest password accepted, in bytes. bcrypt ignores
+// anything after the first 72 bytes, so longer passwords are rejected rather
+// than silently truncated.
+const MaxLength = 72
+
+// Hasher hashes passwords with bcrypt at a fixed cost.
+type Hasher struct {
+	cost int
+
+	// unknownHash is compared against when there is no stored hash, so that
+	// checking the password of a user that does not exist takes as long as
+	// checking one that does.
+	unknownHash []byte
+}
+
+// New creates a new Hasher using the provided bcrypt cost and returns a pointer
+// to it. Each increment of the cost doubles the time taken to hash.
+func New(cost int) (*Hasher, error) {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("[in password.New] cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	unknownHash, err := bcrypt.GenerateFromPassword([]byte("unknown"), cost)
+	if err != nil {
+		return nil, fmt.Errorf("[in password.New] failed to hash: %w", err)
+	}
+
+	return &Hasher{cost: cost, unknownHash: unknownHash}, nil
+}
+
+// Hash returns the hash of the provided password to store.
+func (h *Hasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("[in password.Hasher.Hash] failed to hash password: %w", err)
+	}
+
+	return string(hash), nil
+}
+
+// Verify reports whether password matches the stored hash. An empty hash never
+// matches, but takes as long to check as a real one.
+func (h *Hasher) Verify(hash string, password string) (bool, error) {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(h.unknownHash, []byte(password))
+		return false, nil
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("[in password.Hasher.Verify] failed to compare password: %w", err)
+	}
+}
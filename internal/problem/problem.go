@@ -0,0 +1,69 @@
+// Package problem writes error responses as RFC 9457 problem details, so that
+// every error returned by the API has the same machine readable shape.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jha-captech/blog/internal/requestid"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// Details is an RFC 9457 problem details object describing why a request
+// failed.
+type Details struct {
+	// Type identifies the kind of problem. It is "about:blank" when the
+	// status code says everything there is to know.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that failed.
+	Instance string `json:"instance,omitempty"`
+	// TraceID and RequestID identify the request in traces and logs, for
+	// support requests. TraceID is empty when tracing is disabled, while
+	// RequestID is set for every request and matches the X-Request-ID header.
+	TraceID   string `json:"trace_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Problems maps each invalid field to what is wrong with it, for requests
+	// that failed validation.
+	Problems map[string]string `json:"problems,omitempty"`
+}
+
+// New returns the problem details of a response with the provided status and
+// detail to r.
+func New(r *http.Request, status int, detail string) Details {
+	details := Details{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: requestid.FromContext(r.Context()),
+	}
+
+	if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+		details.TraceID = sc.TraceID().String()
+	}
+
+	return details
+}
+
+// Write writes details as the response, with its status code.
+func Write(w http.ResponseWriter, details Details) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(details.Status)
+	_ = json.NewEncoder(w).Encode(details)
+}
+
+// Error writes a problem details response with the provided status and detail.
+// It replaces http.Error, which writes plain text.
+func Error(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	Write(w, New(r, status, detail))
+}
@@ -0,0 +1,121 @@
+// Package ratelimit limits how often each client can make requests, using a
+// token bucket per client that refills at a steady rate.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// Limit is the rate requests are allowed at: Requests per Per, which may all
+// be made at once.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses a limit written as requests/duration, e.g. "100/1m".
+func ParseLimit(s string) (Limit, error) {
+	requests, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must be written as requests/duration, e.g. 100/1m", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must allow a positive number of requests", s)
+	}
+
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("[in ratelimit.ParseLimit] limit %q must have a positive duration", s)
+	}
+
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Store tracks the requests made by each client.
+type Store interface {
+	// Take takes a request from the bucket of key under limit. When the bucket
+	// is empty the request is not allowed, and retryAfter is how long until
+	// the next one would be.
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// sweepInterval is how often MemoryStore forgets the buckets of clients that
+// have stopped making requests.
+const sweepInterval = time.Minute
+
+// MemoryStore is a Store keeping buckets in memory, so every instance of the
+// server enforces limits on its own.
+type MemoryStore struct {
+	clock clock.Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket holds the requests a client has left as of updated.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// NewMemoryStore creates a new MemoryStore and returns a pointer to it.
+func NewMemoryStore(clk clock.Clock) *MemoryStore {
+	return &MemoryStore{
+		clock:     clk,
+		buckets:   make(map[string]*bucket),
+		lastSweep: clk.Now(),
+	}
+}
+
+// Take takes a request from the bucket of key under limit. It never fails.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := s.clock.Now()
+	rate := float64(limit.Requests) / float64(limit.Per)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	// Refill for the time since the bucket was last used.
+	b.tokens = min(float64(limit.Requests), b.tokens+float64(now.Sub(b.updated))*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate), nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep forgets the buckets that have refilled completely, as they are the
+// same as new ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.limit.Per {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,154 @@
+// Package realip determines the address of the client that sent a request,
+// trusting forwarding headers only when they were set by a known proxy.
+package realip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver finds the client address of requests received through a chain of
+// trusted proxies.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// New creates a new Resolver trusting the provided CIDR ranges and returns a
+// pointer to it. Single addresses are accepted as well as ranges.
+func New(trustedCIDRs []string) (*Resolver, error) {
+	trusted := make([]netip.Prefix, 0, len(trustedCIDRs))
+	for _, cidr := range trustedCIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("[in realip.New] invalid trusted proxy %q: %w", cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	return &Resolver{trusted: trusted}, nil
+}
+
+// ClientIP returns the address of the client that sent r. Forwarding headers
+// are only read when the request came from a trusted proxy, and are walked
+// from the nearest hop back, stopping at the first untrusted address. The
+// Forwarded header is preferred over X-Forwarded-For, and X-Real-IP is used
+// when neither is set.
+func (res *Resolver) ClientIP(r *http.Request) netip.Addr {
+	peer := parseAddr(r.RemoteAddr)
+	if !peer.IsValid() || !res.isTrusted(peer) {
+		return peer
+	}
+
+	var hops []string
+	switch {
+	case r.Header.Get("Forwarded") != "":
+		hops = forwardedFor(r.Header.Values("Forwarded"))
+	case r.Header.Get("X-Forwarded-For") != "":
+		hops = splitList(r.Header.Values("X-Forwarded-For"))
+	case r.Header.Get("X-Real-IP") != "":
+		if addr := parseAddr(r.Header.Get("X-Real-IP")); addr.IsValid() {
+			return addr
+		}
+		return peer
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr := parseAddr(hops[i])
+		if !addr.IsValid() {
+			// Obfuscated or malformed hop, the trusted proxy that added it is
+			// the furthest address known.
+			return client
+		}
+
+		client = addr
+		if !res.isTrusted(addr) {
+			return client
+		}
+	}
+
+	return client
+}
+
+// isTrusted reports whether addr belongs to a trusted proxy.
+func (res *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the for= parameters of Forwarded header values, in the
+// order the hops were added.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hops = append(hops, value)
+			}
+		}
+	}
+	return hops
+}
+
+// splitList splits comma separated header values into their elements.
+func splitList(values []string) []string {
+	var elements []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.TrimSpace(element); element != "" {
+				elements = append(elements, element)
+			}
+		}
+	}
+	return elements
+}
+
+// parseAddr parses an address with an optional port, as found in RemoteAddr
+// and forwarding headers. An invalid address is returned if s is not an IP.
+func parseAddr(s string) netip.Addr {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// contextKey is the type of the context key used to store the client address.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the client address.
+func NewContext(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, contextKey{}, addr)
+}
+
+// FromContext returns the client address stored in ctx, if any.
+func FromContext(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(contextKey{}).(netip.Addr)
+	return addr, ok
+}
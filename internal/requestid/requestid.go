@@ -0,0 +1,55 @@
+// Package requestid identifies the requests served by the API, so every log
+// line written while serving one can be tied back to it.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the header request ids are read from and sent back in.
+const Header = "X-Request-ID"
+
+// maxLength is the longest request id accepted from a client.
+const maxLength = 128
+
+// New returns a new random request id.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether id is safe to reuse as a request id: not empty, not
+// too long, and made only of letters, digits and the characters - _ . : so it
+// can't break up or inject log lines.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// contextKey is the key the request id is stored under in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx, or an empty string when
+// there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
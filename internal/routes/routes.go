@@ -3,15 +3,64 @@ package routes
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/jha-captech/blog/cmd/api/docs"
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/embeddings"
 	"github.com/jha-captech/blog/internal/handlers"
+	"github.com/jha-captech/blog/internal/health"
+	"github.com/jha-captech/blog/internal/lint"
+	"github.com/jha-captech/blog/internal/metrics"
+	"github.com/jha-captech/blog/internal/middleare"
+	"github.com/jha-captech/blog/internal/overload"
+	"github.com/jha-captech/blog/internal/security/events"
 	"github.com/jha-captech/blog/internal/services"
+	"github.com/jha-captech/blog/internal/slo"
 	"github.com/swaggo/http-swagger/v2"
-
-	_ "github.com/jha-captech/blog/cmd/api/docs"
 )
 
-// AddRoutes adds all routes to the provided mux.
+// Options holds the settings applied to routes by AddRoutes.
+type Options struct {
+	// HealthzCacheTTL is how long /healthz responses are reused.
+	HealthzCacheTTL time.Duration
+	// AnnouncementsCacheTTL is how long active announcement responses are
+	// reused.
+	AnnouncementsCacheTTL time.Duration
+	// Bulkheads limit the concurrent requests to the "users", "posts",
+	// "events" and "admin" route groups.
+	Bulkheads middleare.Bulkheads
+	// RateLimits limit the requests each client can make to the same route
+	// groups as Bulkheads.
+	RateLimits middleare.RateLimits
+	// JSONDecoding sets whether the same route groups reject unknown fields
+	// in request bodies.
+	JSONDecoding middleare.JSONDecoding
+	// Shedder rejects requests by priority when the server is overloaded.
+	Shedder *overload.Shedder
+	// Tokens issues bearer tokens at login and verifies them on the routes
+	// that require a logged in user.
+	Tokens *auth.Tokens
+	// AdminUserIDs are the users allowed to use the admin routes.
+	AdminUserIDs []uint
+	// CSRF protects the routes browser clients change state with.
+	CSRF middleare.CSRF
+	// CORS lets browser clients served from other origins call the routes.
+	CORS middleare.CORS
+	// SecurityEvents records failed logins, permission denials and admin
+	// actions.
+	SecurityEvents *events.Emitter
+	// Metrics holds the metrics served to Prometheus.
+	Metrics *metrics.Registry
+	// Embedder generates the embeddings of semantic search queries. Searches
+	// fall back to full text matching when it is nil.
+	Embedder embeddings.Provider
+	// Linter checks post drafts for problems.
+	Linter *lint.Linter
+}
+
+// AddRoutes adds all routes to the provided mux, and returns it wrapped in the
+// CORS policy, which answers preflight requests before they are routed.
 //
 //	@title						Blog Service API
 //	@version					1.0
@@ -26,14 +75,316 @@ import (
 //	@BasePath					/api
 //	@externalDocs.description	OpenAPI
 //	@externalDocs.url			https://swagger.io/resources/open-api/
-func AddRoutes(mux *http.ServeMux, logger *slog.Logger, usersService *services.UsersService, baseURL string) {
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Bearer token from POST /api/login, as "Bearer <token>"
+func AddRoutes(
+	mux *http.ServeMux,
+	logger *slog.Logger,
+	usersService *services.UsersService,
+	eventsService *services.EventsService,
+	settingsService *services.SettingsService,
+	announcementsService *services.AnnouncementsService,
+	postsService *services.PostsService,
+	commentsService *services.CommentsService,
+	cspReportsService *services.CSPReportsService,
+	canariesService *services.CanariesService,
+	dependenciesService *services.DependenciesService,
+	summariesService *services.SummariesService,
+	sloTracker *slo.Tracker,
+	healthMonitor *health.Monitor,
+	options Options,
+) http.Handler {
+	// Route groups sharing a concurrency limit, a rate limit per client and
+	// how strictly request bodies are decoded. Rate limits are checked first,
+	// so clients over their limit don't take up concurrency slots.
+	group := func(name string) middleare.Middleware {
+		rateLimited, bulkhead := options.RateLimits.Group(name), options.Bulkheads.Group(name)
+		decoding := options.JSONDecoding.Group(name)
+		return func(next http.Handler) http.Handler {
+			return rateLimited(bulkhead(decoding(next)))
+		}
+	}
+	usersGroup := group("users")
+	postsGroup := group("posts")
+	eventsGroup := group("events")
+	adminGroup := group("admin")
+
+	// Request priorities under overload. High priority requests are never
+	// shed but count towards the load.
+	highPriority := middleare.Shed(logger, options.Shedder, overload.PriorityHigh)
+	normalPriority := middleare.Shed(logger, options.Shedder, overload.PriorityNormal)
+	lowPriority := middleare.Shed(logger, options.Shedder, overload.PriorityLow)
+
+	// Routes requiring a logged in user
+	authenticated := middleare.Authenticate(logger, options.Tokens, options.SecurityEvents)
+
+	// Routes requiring a logged in administrator
+	requireAdmin := middleare.RequireAdmin(options.AdminUserIDs, options.SecurityEvents)
+	admin := func(next http.Handler) http.Handler {
+		return authenticated(requireAdmin(next))
+	}
+
+	// Unsafe requests from browser clients must echo a CSRF token. Analytics
+	// events are exempt, as they are sent with navigator.sendBeacon, which
+	// cannot set headers, and only record page views.
+	csrfProtected := options.CSRF.Protect()
+
+	// Changes made through the admin routes are recorded as security events,
+	// along with the administrator who made them
+	audited := middleare.Audit(options.SecurityEvents)
+
+	// Issue a CSRF token for browser clients
+	mux.Handle("GET /api/csrf", normalPriority(handlers.HandleCSRFToken(logger, options.CSRF)))
+
+	// Exchange credentials for a bearer token
+	mux.Handle(
+		"POST /api/login",
+		normalPriority(usersGroup(csrfProtected(
+			handlers.HandleLogin(logger, usersService, options.Tokens, canariesService, options.SecurityEvents),
+		))),
+	)
+
+	// Public keys bearer tokens are verified with
+	mux.Handle("GET /.well-known/jwks.json", highPriority(handlers.HandleJWKS(logger, options.Tokens)))
+
+	// List users
+	mux.Handle(
+		"GET /api/users",
+		lowPriority(usersGroup(handlers.HandleListUsers(logger, usersService, canariesService, options.SecurityEvents))),
+	)
+
+	// Search users by name
+	mux.Handle(
+		"GET /api/users/search",
+		lowPriority(usersGroup(handlers.HandleSearchUsers(
+			logger,
+			usersService,
+			dependenciesService,
+			canariesService,
+			options.SecurityEvents,
+		))),
+	)
+
+	// Create a user
+	mux.Handle("POST /api/users", normalPriority(usersGroup(csrfProtected(handlers.HandleCreateUser(logger, usersService)))))
+
 	// Read a user
-	mux.Handle("GET /api/users/{id}", handlers.HandleReadUser(logger, usersService))
+	mux.Handle(
+		"GET /api/users/{id}",
+		highPriority(usersGroup(handlers.HandleReadUser(logger, usersService, canariesService, options.SecurityEvents))),
+	)
+
+	// Update a user
+	mux.Handle(
+		"PUT /api/users/{id}",
+		normalPriority(usersGroup(csrfProtected(authenticated(
+			handlers.HandleUpdateUser(logger, usersService, options.SecurityEvents),
+		)))),
+	)
+
+	// Delete a user
+	mux.Handle(
+		"DELETE /api/users/{id}",
+		normalPriority(usersGroup(csrfProtected(authenticated(
+			handlers.HandleDeleteUser(logger, usersService, options.SecurityEvents),
+		)))),
+	)
+
+	// Users changed since a sync token, for incremental client syncs. The
+	// changes include every user's email, so only administrators can sync.
+	mux.Handle(
+		"GET /api/sync",
+		lowPriority(usersGroup(admin(handlers.HandleSync(logger, usersService, canariesService, options.SecurityEvents)))),
+	)
+
+	// List posts
+	mux.Handle("GET /api/posts", lowPriority(postsGroup(handlers.HandleListPosts(logger, postsService))))
+
+	// Create a post
+	mux.Handle(
+		"POST /api/posts",
+		normalPriority(postsGroup(csrfProtected(authenticated(handlers.HandleCreatePost(logger, postsService))))),
+	)
+
+	// Search posts by meaning
+	mux.Handle(
+		"GET /api/posts/semantic-search",
+		lowPriority(postsGroup(handlers.HandleSemanticSearchPosts(logger, postsService, options.Embedder, dependenciesService))),
+	)
+
+	// Read a post
+	mux.Handle("GET /api/posts/{id}", highPriority(postsGroup(handlers.HandleReadPost(logger, postsService))))
+
+	// Update a post
+	mux.Handle(
+		"PUT /api/posts/{id}",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleUpdatePost(logger, postsService, postsService, options.SecurityEvents),
+		)))),
+	)
+
+	// Delete a post
+	mux.Handle(
+		"DELETE /api/posts/{id}",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleDeletePost(logger, postsService, postsService, options.SecurityEvents),
+		)))),
+	)
+
+	// List the posts related to a post
+	mux.Handle(
+		"GET /api/posts/{id}/related",
+		lowPriority(postsGroup(handlers.HandleRelatedPosts(logger, postsService, dependenciesService))),
+	)
+
+	// Set the embedding posts are related by
+	mux.Handle(
+		"PUT /api/posts/{id}/embedding",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleUpdatePostEmbedding(logger, postsService, postsService, options.SecurityEvents),
+		)))),
+	)
+
+	// Summarize a post with the language model
+	mux.Handle(
+		"POST /api/posts/{id}/summarize",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleSummarizePost(logger, postsService, summariesService, options.SecurityEvents),
+		)))),
+	)
+
+	// Check a post draft for problems
+	mux.Handle(
+		"POST /api/posts/{id}/lint",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleLintPost(logger, postsService, options.Linter, options.SecurityEvents),
+		)))),
+	)
+
+	// List the comments on a post
+	mux.Handle("GET /api/posts/{id}/comments", lowPriority(postsGroup(handlers.HandleListComments(logger, commentsService))))
+
+	// Comment on a post
+	mux.Handle(
+		"POST /api/posts/{id}/comments",
+		normalPriority(postsGroup(csrfProtected(authenticated(handlers.HandleCreateComment(logger, commentsService))))),
+	)
+
+	// Delete a comment
+	mux.Handle(
+		"DELETE /api/posts/{id}/comments/{commentID}",
+		normalPriority(postsGroup(csrfProtected(authenticated(
+			handlers.HandleDeleteComment(logger, postsService, commentsService, commentsService, options.SecurityEvents),
+		)))),
+	)
+
+	// Record a batch of client-side analytics events
+	mux.Handle("POST /api/events", lowPriority(eventsGroup(handlers.HandleCreateEvents(logger, eventsService))))
+
+	// Content Security Policy violations reported by browsers, which send them
+	// without CSRF tokens
+	mux.Handle("POST /api/csp-reports", lowPriority(eventsGroup(handlers.HandleCreateCSPReports(logger, cspReportsService))))
 
-	// swagger docs
+	// Announcements currently shown as banners, requested on every page load
+	mux.Handle(
+		"GET /api/announcements/active",
+		middleare.Memoize(options.AnnouncementsCacheTTL)(handlers.HandleActiveAnnouncements(logger, announcementsService)),
+	)
+
+	// JSON Schema of request and response bodies
+	mux.Handle("GET /api/schema/{resource}", handlers.HandleSchema(logger))
+
+	// Blog-level settings. Every page reads them to render the blog, so reading
+	// them is public.
+	mux.Handle("GET /api/admin/settings", normalPriority(adminGroup(handlers.HandleReadSettings(logger, settingsService))))
+	mux.Handle(
+		"PUT /api/admin/settings",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleUpdateSettings(logger, settingsService)))))),
+	)
+
+	// Most frequent Content Security Policy violations, which reveal the URLs
+	// the blog's pages are attacked from
+	mux.Handle(
+		"GET /api/admin/csp-reports",
+		normalPriority(adminGroup(admin(handlers.HandleCSPReportSummary(logger, cspReportsService)))),
+	)
+
+	// Manage announcements. The list includes scheduled announcements that are
+	// not public yet.
+	mux.Handle(
+		"GET /api/admin/announcements",
+		normalPriority(adminGroup(admin(handlers.HandleListAnnouncements(logger, announcementsService)))),
+	)
+	mux.Handle(
+		"POST /api/admin/announcements",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleCreateAnnouncement(logger, announcementsService)))))),
+	)
+	mux.Handle(
+		"PUT /api/admin/announcements/{id}",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleUpdateAnnouncement(logger, announcementsService)))))),
+	)
+	mux.Handle(
+		"DELETE /api/admin/announcements/{id}",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleDeleteAnnouncement(logger, announcementsService)))))),
+	)
+
+	// Manage canaries, decoy users whose use raises a security alert
+	mux.Handle("GET /api/admin/canaries", normalPriority(adminGroup(admin(handlers.HandleListCanaries(logger, canariesService)))))
+	mux.Handle(
+		"POST /api/admin/canaries",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleCreateCanary(logger, canariesService)))))),
+	)
+	mux.Handle(
+		"POST /api/admin/canaries/{id}/rotate",
+		normalPriority(adminGroup(csrfProtected(admin(audited(handlers.HandleRotateCanary(logger, canariesService)))))),
+	)
+
+	// SLO attainment report
+	mux.Handle("GET /api/admin/slo", normalPriority(adminGroup(admin(handlers.HandleSLOReport(logger, sloTracker)))))
+
+	// Load and shed requests, kept out of the admin route group's limits so it
+	// stays available to diagnose overload
+	mux.Handle("GET /api/admin/load", highPriority(admin(handlers.HandleLoadReport(logger, options.Shedder))))
+
+	// Health check for orchestrator probes. Responses are shared between
+	// concurrent probes and briefly reused, so probe storms during an incident
+	// do not add load to the dependencies being checked.
+	mux.Handle(
+		"GET /healthz",
+		middleare.Memoize(options.HealthzCacheTTL)(handlers.HandleHealthz(logger, healthMonitor)),
+	)
+
+	// Prometheus scrapes, kept available to diagnose overload
+	mux.Handle("GET /metrics", highPriority(handlers.HandleMetrics(logger, options.Metrics)))
+
+	// Recent dependency health check results, including their error details
+	mux.Handle(
+		"GET /api/admin/health/history",
+		normalPriority(adminGroup(admin(handlers.HandleHealthHistory(logger, healthMonitor)))),
+	)
+
+	// Dependency versions and the optional features they provide, which tell
+	// attackers which known vulnerabilities to try
+	mux.Handle(
+		"GET /api/admin/dependencies",
+		normalPriority(adminGroup(admin(handlers.HandleDependencies(logger, dependenciesService)))),
+	)
+
+	// swagger docs, cached in memory and by clients as they never change while
+	// the server is running. The spec is embedded in the binary and the UI
+	// loads it from a relative URL, so the docs work on any host.
+	mux.Handle(
+		"GET /swagger/doc.json",
+		middleare.StaticCache(time.Hour)(handlers.HandleSwaggerSpec(logger, docs.Files)),
+	)
 	mux.Handle(
 		"GET /swagger/",
-		httpSwagger.Handler(httpSwagger.URL(baseURL+"/swagger/doc.json")),
+		middleare.StaticCache(time.Hour)(
+			httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")),
+		),
 	)
-	logger.Info("Swagger running", slog.String("url", baseURL+"/swagger/index.html"))
+
+	return options.CORS.Handler(mux)(mux)
 }
@@ -0,0 +1,239 @@
+// Package events records security relevant events, such as failed logins and
+// permission denials, to a dedicated log stream, and alerts a webhook about
+// the serious ones.
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/auth"
+	"github.com/jha-captech/blog/internal/clock"
+	"github.com/jha-captech/blog/internal/httpclient"
+	"github.com/jha-captech/blog/internal/realip"
+)
+
+// Types of security events.
+const (
+	TypeLoginFailed      = "login_failed"
+	TypeTokenRejected    = "token_rejected"
+	TypePermissionDenied = "permission_denied"
+	TypeCSRFRejected     = "csrf_rejected"
+	TypeAdminAction      = "admin_action"
+	TypeRateLimited      = "rate_limited"
+	TypeCanaryTriggered  = "canary_triggered"
+)
+
+// Severity is how urgently a security event needs attention.
+type Severity int
+
+// Supported severities, in increasing order.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// ParseSeverity returns the severity named "info", "warning" or "critical".
+func ParseSeverity(name string) (Severity, error) {
+	for s := SeverityInfo; s <= SeverityCritical; s++ {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("[in events.ParseSeverity] unknown severity %q", name)
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// level returns the log level events of the severity are logged at.
+func (s Severity) level() slog.Level {
+	switch s {
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// Event is a security relevant occurrence.
+type Event struct {
+	Type     string
+	Severity Severity
+	Message  string
+	// Attrs holds details specific to the event type, e.g. the email address
+	// of a failed login.
+	Attrs map[string]string
+}
+
+// Options holds the settings used to construct an Emitter.
+type Options struct {
+	// WebhookURL receives a JSON alert for events of AlertSeverity or higher.
+	// No alerts are sent when it is empty.
+	WebhookURL    string
+	AlertSeverity Severity
+	// AlertThrottle is the minimum time between two alerts for the same event
+	// type. Events in between are counted and reported with the next alert.
+	AlertThrottle time.Duration
+}
+
+// Emitter records security events. Every event is logged, along with the user
+// and client address of the request it happened in, and serious events are
+// sent to a webhook, throttled per event type so that an attack does not
+// flood whoever is on call.
+type Emitter struct {
+	logger  *slog.Logger
+	clock   clock.Clock
+	client  *httpclient.Client
+	options Options
+
+	mu         sync.Mutex
+	lastAlert  map[string]time.Time
+	suppressed map[string]int
+}
+
+// NewEmitter creates a new Emitter logging to the provided logger and sending
+// alerts with the provided client, and returns a pointer to it.
+func NewEmitter(logger *slog.Logger, clk clock.Clock, client *httpclient.Client, options Options) *Emitter {
+	return &Emitter{
+		logger:     logger,
+		clock:      clk,
+		client:     client,
+		options:    options,
+		lastAlert:  make(map[string]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// alert is the body of a webhook alert.
+type alert struct {
+	Type       string            `json:"type"`
+	Severity   string            `json:"severity"`
+	Message    string            `json:"message"`
+	UserID     uint              `json:"user_id,omitempty"`
+	ClientIP   string            `json:"client_ip,omitempty"`
+	Attrs      map[string]string `json:"attrs,omitempty"`
+	Time       time.Time         `json:"time"`
+	Suppressed int               `json:"suppressed"`
+}
+
+// Emit records the provided event, which happened while handling the request
+// with the provided context. Events emitted to a nil Emitter are dropped.
+func (e *Emitter) Emit(ctx context.Context, event Event) {
+	if e == nil {
+		return
+	}
+
+	a := alert{
+		Type:     event.Type,
+		Severity: event.Severity.String(),
+		Message:  event.Message,
+		Attrs:    event.Attrs,
+		Time:     e.clock.Now(),
+	}
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		a.UserID = userID
+	}
+	if addr, ok := realip.FromContext(ctx); ok && addr.IsValid() {
+		a.ClientIP = addr.String()
+	}
+
+	attrs := []slog.Attr{
+		slog.String("event_type", a.Type),
+		slog.String("severity", a.Severity),
+	}
+	if a.UserID != 0 {
+		attrs = append(attrs, slog.Uint64("user_id", uint64(a.UserID)))
+	}
+	if a.ClientIP != "" {
+		attrs = append(attrs, slog.String("client_ip", a.ClientIP))
+	}
+	for _, key := range slices.Sorted(maps.Keys(a.Attrs)) {
+		attrs = append(attrs, slog.String(key, a.Attrs[key]))
+	}
+	e.logger.LogAttrs(ctx, event.Severity.level(), a.Message, attrs...)
+
+	if e.options.WebhookURL == "" || event.Severity < e.options.AlertSeverity {
+		return
+	}
+
+	suppressed, ok := e.throttle(a.Type, a.Time)
+	if !ok {
+		return
+	}
+	a.Suppressed = suppressed
+
+	// Alerts are sent in the background so a slow webhook never delays the
+	// response, and outlive the request they were raised in.
+	go e.send(context.WithoutCancel(ctx), a)
+}
+
+// throttle reports whether an alert for the event type may be sent at now,
+// along with the number of alerts suppressed since the last one was sent.
+func (e *Emitter) throttle(eventType string, now time.Time) (int, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if last, ok := e.lastAlert[eventType]; ok && now.Sub(last) < e.options.AlertThrottle {
+		e.suppressed[eventType]++
+		return 0, false
+	}
+
+	suppressed := e.suppressed[eventType]
+	e.lastAlert[eventType] = now
+	delete(e.suppressed, eventType)
+
+	return suppressed, true
+}
+
+// send posts the alert to the webhook, logging any failure.
+func (e *Emitter) send(ctx context.Context, a alert) {
+	body, err := json.Marshal(a)
+	if err != nil {
+		e.logger.ErrorContext(ctx, "failed to encode security alert", slog.String("error", err.Error()))
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.options.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		e.logger.ErrorContext(ctx, "failed to create security alert request", slog.String("error", err.Error()))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		e.logger.ErrorContext(ctx, "failed to send security alert", slog.String("error", err.Error()))
+		return
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		e.logger.ErrorContext(
+			ctx,
+			"security alert webhook rejected alert",
+			slog.Int("status", resp.StatusCode),
+		)
+	}
+}
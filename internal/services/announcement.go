@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// announcementColumns are the columns read into a models.Announcement by
+// scanAnnouncement.
+const announcementColumns = `id, message, audience, starts_at, ends_at, created_at, updated_at`
+
+// AnnouncementsService is a service capable of performing CRUD operations for
+// models.Announcement models.
+type AnnouncementsService struct {
+	logger *slog.Logger
+	db     *database.DB
+}
+
+// NewAnnouncementsService creates a new AnnouncementsService and returns a
+// pointer to it.
+func NewAnnouncementsService(logger *slog.Logger, db *database.DB) *AnnouncementsService {
+	return &AnnouncementsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreateAnnouncement attempts to create the provided announcement, returning a
+// fully hydrated models.Announcement or an error.
+func (s *AnnouncementsService) CreateAnnouncement(ctx context.Context, announcement models.Announcement) (models.Announcement, error) {
+	s.logger.DebugContext(ctx, "Creating announcement")
+
+	id, err := s.db.InsertReturningID(
+		ctx,
+		`
+		INSERT INTO announcements (message, audience, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4)
+		`,
+		announcement.Message,
+		announcement.Audience,
+		s.db.Dialect.TimeArg(announcement.StartsAt),
+		s.db.Dialect.TimeArg(announcement.EndsAt),
+	)
+	if err != nil {
+		return models.Announcement{}, fmt.Errorf(
+			"[in services.AnnouncementsService.CreateAnnouncement] failed to create announcement: %w",
+			err,
+		)
+	}
+
+	created, err := s.ReadAnnouncement(ctx, uint64(id))
+	if err != nil {
+		return models.Announcement{}, fmt.Errorf("[in services.AnnouncementsService.CreateAnnouncement] %w", err)
+	}
+
+	return created, nil
+}
+
+// ReadAnnouncement attempts to read the announcement with the provided id,
+// returning it or an error, ErrNotFound if no announcement has the id.
+func (s *AnnouncementsService) ReadAnnouncement(ctx context.Context, id uint64) (models.Announcement, error) {
+	s.logger.DebugContext(ctx, "Reading announcement", "id", id)
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+announcementColumns+` FROM announcements WHERE id = $1`, id)
+
+	announcement, err := scanAnnouncement(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Announcement{}, fmt.Errorf(
+				"[in services.AnnouncementsService.ReadAnnouncement] announcement %d: %w",
+				id,
+				ErrNotFound,
+			)
+		}
+		return models.Announcement{}, fmt.Errorf(
+			"[in services.AnnouncementsService.ReadAnnouncement] failed to read announcement: %w",
+			err,
+		)
+	}
+
+	return announcement, nil
+}
+
+// UpdateAnnouncement attempts to replace the announcement with the provided id,
+// returning the updated models.Announcement or an error, ErrNotFound if no
+// announcement has the id.
+func (s *AnnouncementsService) UpdateAnnouncement(
+	ctx context.Context,
+	id uint64,
+	patch models.Announcement,
+) (models.Announcement, error) {
+	s.logger.DebugContext(ctx, "Updating announcement", "id", id)
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`
+		UPDATE announcements
+		SET message = $1,
+		    audience = $2,
+		    starts_at = $3,
+		    ends_at = $4,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		`,
+		patch.Message,
+		patch.Audience,
+		s.db.Dialect.TimeArg(patch.StartsAt),
+		s.db.Dialect.TimeArg(patch.EndsAt),
+		id,
+	)
+	if err != nil {
+		return models.Announcement{}, fmt.Errorf(
+			"[in services.AnnouncementsService.UpdateAnnouncement] failed to update announcement: %w",
+			err,
+		)
+	}
+
+	updated, err := s.ReadAnnouncement(ctx, id)
+	if err != nil {
+		return models.Announcement{}, fmt.Errorf("[in services.AnnouncementsService.UpdateAnnouncement] %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteAnnouncement attempts to delete the announcement with the provided id,
+// returning an error, ErrNotFound if no announcement has the id.
+func (s *AnnouncementsService) DeleteAnnouncement(ctx context.Context, id uint64) error {
+	s.logger.DebugContext(ctx, "Deleting announcement", "id", id)
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("[in services.AnnouncementsService.DeleteAnnouncement] failed to delete announcement: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[in services.AnnouncementsService.DeleteAnnouncement] failed to read deleted rows: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("[in services.AnnouncementsService.DeleteAnnouncement] announcement %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListAnnouncements attempts to list every announcement, most recently started
+// first.
+func (s *AnnouncementsService) ListAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	s.logger.DebugContext(ctx, "Listing announcements")
+
+	return s.query(
+		ctx,
+		"[in services.AnnouncementsService.ListAnnouncements]",
+		`SELECT `+announcementColumns+` FROM announcements ORDER BY starts_at DESC, id DESC`,
+	)
+}
+
+// ListActiveAnnouncements attempts to list the announcements shown at the
+// provided time to the provided audience, including those targeting everyone.
+func (s *AnnouncementsService) ListActiveAnnouncements(
+	ctx context.Context,
+	at time.Time,
+	audience string,
+) ([]models.Announcement, error) {
+	s.logger.DebugContext(ctx, "Listing active announcements", "audience", audience)
+
+	return s.query(
+		ctx,
+		"[in services.AnnouncementsService.ListActiveAnnouncements]",
+		`
+		SELECT `+announcementColumns+`
+		FROM announcements
+		WHERE starts_at <= $1
+		  AND ends_at > $1
+		  AND (audience = $2 OR audience = $3)
+		ORDER BY starts_at DESC, id DESC
+		`,
+		s.db.Dialect.TimeArg(at),
+		models.AudienceAll,
+		audience,
+	)
+}
+
+// query runs a query returning announcements, prefixing errors with the
+// caller's location.
+func (s *AnnouncementsService) query(ctx context.Context, in string, query string, args ...any) ([]models.Announcement, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed to query announcements: %w", in, err)
+	}
+	defer rows.Close()
+
+	announcements := make([]models.Announcement, 0)
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed to scan announcement: %w", in, err)
+		}
+		announcements = append(announcements, announcement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s failed to read announcements: %w", in, err)
+	}
+
+	return announcements, nil
+}
+
+// scanAnnouncement scans a row selected with announcementColumns.
+func scanAnnouncement(row interface{ Scan(dest ...any) error }) (models.Announcement, error) {
+	var announcement models.Announcement
+	err := row.Scan(
+		&announcement.ID,
+		&announcement.Message,
+		&announcement.Audience,
+		&announcement.StartsAt,
+		&announcement.EndsAt,
+		&announcement.CreatedAt,
+		&announcement.UpdatedAt,
+	)
+	return announcement, err
+}
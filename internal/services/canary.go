@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// Names canary users are generated from, so they look like any other user.
+var (
+	canaryFirstNames = []string{
+		"Adam", "Chloe", "Daniel", "Grace", "Henry", "Isabel", "Jacob", "Laura",
+		"Marcus", "Nora", "Owen", "Priya", "Ryan", "Sofia", "Thomas", "Zoe",
+	}
+	canaryLastNames = []string{
+		"Anderson", "Bennett", "Carter", "Evans", "Foster", "Harris", "Hughes", "Kim",
+		"Morgan", "Nguyen", "Patel", "Reed", "Shaw", "Turner", "Walsh", "Young",
+	}
+)
+
+// CanariesService is a service capable of managing canaries, decoy users
+// whose credentials can be planted where an attacker would find them.
+type CanariesService struct {
+	logger      *slog.Logger
+	db          *database.DB
+	users       *UsersService
+	emailDomain string
+}
+
+// NewCanariesService creates a new CanariesService creating canary users
+// through the provided UsersService, with email addresses at emailDomain, and
+// returns a pointer to it.
+func NewCanariesService(logger *slog.Logger, db *database.DB, users *UsersService, emailDomain string) *CanariesService {
+	return &CanariesService{
+		logger:      logger,
+		db:          db,
+		users:       users,
+		emailDomain: emailDomain,
+	}
+}
+
+// ListCanaries attempts to list every canary, oldest first, returning them or
+// an error.
+func (s *CanariesService) ListCanaries(ctx context.Context) ([]models.Canary, error) {
+	s.logger.DebugContext(ctx, "Listing canaries")
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`
+		SELECT u.id,
+		       u.name,
+		       u.email,
+		       c.created_at
+		FROM canaries c
+		JOIN users u ON u.id = c.user_id
+		ORDER BY c.created_at, u.id
+		`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.CanariesService.ListCanaries] failed to list canaries: %w", err)
+	}
+	defer rows.Close()
+
+	canaries := []models.Canary{}
+	for rows.Next() {
+		var canary models.Canary
+		if err := rows.Scan(&canary.UserID, &canary.Name, &canary.Email, &canary.CreatedAt); err != nil {
+			return nil, fmt.Errorf("[in services.CanariesService.ListCanaries] failed to scan canary: %w", err)
+		}
+		if canary.Email, err = s.users.keyring.Decrypt(canary.Email); err != nil {
+			return nil, fmt.Errorf("[in services.CanariesService.ListCanaries] %w", err)
+		}
+		canaries = append(canaries, canary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.CanariesService.ListCanaries] failed to list canaries: %w", err)
+	}
+
+	return canaries, nil
+}
+
+// CreateCanary attempts to create a canary user with a generated name, email
+// address and password. The canary is returned along with its password, which
+// is not stored and cannot be retrieved later, or an error.
+func (s *CanariesService) CreateCanary(ctx context.Context) (models.Canary, string, error) {
+	s.logger.DebugContext(ctx, "Creating canary")
+
+	var (
+		canary   models.Canary
+		password string
+	)
+	err := s.db.InTx(ctx, func(tx *database.Tx) error {
+		var err error
+		canary, password, err = s.createCanary(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return models.Canary{}, "", fmt.Errorf("[in services.CanariesService.CreateCanary] %w", err)
+	}
+
+	return canary, password, nil
+}
+
+// createCanary creates a canary as described by CreateCanary within tx, so the
+// user is never left behind without the canary marking it.
+func (s *CanariesService) createCanary(ctx context.Context, tx *database.Tx) (models.Canary, string, error) {
+	name, email, err := s.generateIdentity(ctx, tx)
+	if err != nil {
+		return models.Canary{}, "", fmt.Errorf("[in services.CanariesService.createCanary] %w", err)
+	}
+
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return models.Canary{}, "", fmt.Errorf(
+			"[in services.CanariesService.createCanary] failed to generate password: %w",
+			err,
+		)
+	}
+	password := base64.RawURLEncoding.EncodeToString(b)
+
+	user, err := s.users.createUser(ctx, tx, models.User{Name: name, Email: email}, password)
+	if err != nil {
+		return models.Canary{}, "", fmt.Errorf("[in services.CanariesService.createCanary] %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO canaries (user_id) VALUES ($1)`, user.ID); err != nil {
+		return models.Canary{}, "", fmt.Errorf(
+			"[in services.CanariesService.createCanary] failed to create canary: %w",
+			err,
+		)
+	}
+
+	return models.Canary{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}, password, nil
+}
+
+// RotateCanary attempts to replace the canary with the provided user id with a
+// new one, deleting the old canary user. The new canary is returned along with
+// its password, or an error, ErrNotFound if the user is not a canary.
+func (s *CanariesService) RotateCanary(ctx context.Context, userID uint64) (models.Canary, string, error) {
+	s.logger.DebugContext(ctx, "Rotating canary", "user_id", userID)
+
+	// The replacement is created and the old canary deleted together, so a
+	// failure leaves the canaries as they were.
+	var (
+		canary   models.Canary
+		password string
+	)
+	err := s.db.InTx(ctx, func(tx *database.Tx) error {
+		ok, err := s.isCanary(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("canary %d: %w", userID, ErrNotFound)
+		}
+
+		if canary, password, err = s.createCanary(ctx, tx); err != nil {
+			return err
+		}
+
+		return s.users.deleteUser(ctx, tx, userID)
+	})
+	if err != nil {
+		return models.Canary{}, "", fmt.Errorf("[in services.CanariesService.RotateCanary] %w", err)
+	}
+
+	return canary, password, nil
+}
+
+// IsCanary reports whether the user with the provided id is a canary.
+func (s *CanariesService) IsCanary(ctx context.Context, userID uint64) (bool, error) {
+	return s.isCanary(ctx, s.db, userID)
+}
+
+// isCanary reports whether the user with the provided id is a canary, running
+// its query with q so it can take part in a transaction.
+func (s *CanariesService) isCanary(ctx context.Context, q database.Querier, userID uint64) (bool, error) {
+	var one int
+	err := q.QueryRowContext(ctx, `SELECT 1 FROM canaries WHERE user_id = $1`, userID).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("[in services.CanariesService.isCanary] failed to check canary: %w", err)
+	}
+
+	return true, nil
+}
+
+// CanaryUserIDs attempts to list the ids of every canary user, returning them
+// or an error. There are only ever a few canaries, so handlers returning many
+// users check them against this list rather than querying for each user.
+func (s *CanariesService) CanaryUserIDs(ctx context.Context) ([]uint, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT user_id FROM canaries`)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.CanariesService.CanaryUserIDs] failed to list canaries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("[in services.CanariesService.CanaryUserIDs] failed to scan canary: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.CanariesService.CanaryUserIDs] failed to list canaries: %w", err)
+	}
+
+	return ids, nil
+}
+
+// IsCanaryEmail reports whether the provided email address belongs to a
+// canary.
+func (s *CanariesService) IsCanaryEmail(ctx context.Context, email string) (bool, error) {
+	var one int
+	err := s.db.QueryRowContext(
+		ctx,
+		`
+		SELECT 1
+		FROM canaries c
+		JOIN users u ON u.id = c.user_id
+		WHERE u.email_index = $1
+		   OR (u.email_index IS NULL AND LOWER(u.email) = $2)
+		LIMIT 1
+		`,
+		s.users.emailIndex(email),
+		strings.ToLower(email),
+	).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("[in services.CanariesService.IsCanaryEmail] failed to check canary: %w", err)
+	}
+
+	return true, nil
+}
+
+// generateIdentity returns a random name and an email address no user has yet,
+// checked with q. A canary sharing its email address with a real user would
+// flag that user's logins.
+func (s *CanariesService) generateIdentity(ctx context.Context, q database.Querier) (string, string, error) {
+	for range 10 {
+		first, err := pick(canaryFirstNames)
+		if err != nil {
+			return "", "", fmt.Errorf("[in services.CanariesService.generateIdentity] %w", err)
+		}
+		last, err := pick(canaryLastNames)
+		if err != nil {
+			return "", "", fmt.Errorf("[in services.CanariesService.generateIdentity] %w", err)
+		}
+		number, err := rand.Int(rand.Reader, big.NewInt(90))
+		if err != nil {
+			return "", "", fmt.Errorf("[in services.CanariesService.generateIdentity] failed to generate number: %w", err)
+		}
+		email := fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), number.Int64()+10, s.emailDomain)
+
+		taken, err := s.users.emailTaken(ctx, q, email)
+		if err != nil {
+			return "", "", fmt.Errorf("[in services.CanariesService.generateIdentity] %w", err)
+		}
+		if !taken {
+			return first + " " + last, email, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("[in services.CanariesService.generateIdentity] failed to find an unused email address")
+}
+
+// pick returns a random element of values.
+func pick(values []string) (string, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(values))))
+	if err != nil {
+		return "", fmt.Errorf("[in services.pick] failed to pick a random value: %w", err)
+	}
+	return values[i.Int64()], nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// commentColumns are the columns read into a models.Comment by scanComment.
+const commentColumns = `id, post_id, author_id, message, created_at, updated_at`
+
+// CommentsService is a service capable of creating, reading, listing and
+// deleting the models.Comment models of a post.
+type CommentsService struct {
+	logger *slog.Logger
+	db     *database.DB
+}
+
+// NewCommentsService creates a new CommentsService and returns a pointer to
+// it.
+func NewCommentsService(logger *slog.Logger, db *database.DB) *CommentsService {
+	return &CommentsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreateComment attempts to create the provided comment, returning a fully
+// hydrated models.Comment or an error, ErrNotFound if the post does not exist
+// or ErrAuthorNotFound if the author does not.
+func (s *CommentsService) CreateComment(ctx context.Context, comment models.Comment) (models.Comment, error) {
+	s.logger.DebugContext(ctx, "Creating comment", "post_id", comment.PostID, "author_id", comment.AuthorID)
+
+	if err := s.checkPost(ctx, uint64(comment.PostID)); err != nil {
+		return models.Comment{}, fmt.Errorf("[in services.CommentsService.CreateComment] %w", err)
+	}
+
+	// The author is checked up front so a missing one is reported the same way
+	// by every driver, rather than as a driver specific constraint error.
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM users WHERE id = $1`, comment.AuthorID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Comment{}, fmt.Errorf(
+				"[in services.CommentsService.CreateComment] user %d: %w",
+				comment.AuthorID,
+				ErrAuthorNotFound,
+			)
+		}
+		return models.Comment{}, fmt.Errorf("[in services.CommentsService.CreateComment] failed to read author: %w", err)
+	}
+
+	id, err := s.db.InsertReturningID(
+		ctx,
+		`
+		INSERT INTO comments (post_id, author_id, message)
+		VALUES ($1, $2, $3)
+		`,
+		comment.PostID,
+		comment.AuthorID,
+		comment.Message,
+	)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("[in services.CommentsService.CreateComment] failed to create comment: %w", err)
+	}
+
+	// Read the comment back to hydrate the columns set by the database.
+	row := s.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1`, id)
+
+	created, err := scanComment(row)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("[in services.CommentsService.CreateComment] failed to read comment: %w", err)
+	}
+
+	return created, nil
+}
+
+// ReadComment attempts to read the comment with the provided id on the post
+// with the provided id, returning it or an error, ErrNotFound if the post has
+// no such comment.
+func (s *CommentsService) ReadComment(ctx context.Context, postID uint64, id uint64) (models.Comment, error) {
+	s.logger.DebugContext(ctx, "Reading comment", "post_id", postID, "id", id)
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM comments WHERE id = $1 AND post_id = $2`, id, postID)
+
+	comment, err := scanComment(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Comment{}, fmt.Errorf(
+				"[in services.CommentsService.ReadComment] comment %d on post %d: %w",
+				id,
+				postID,
+				ErrNotFound,
+			)
+		}
+		return models.Comment{}, fmt.Errorf("[in services.CommentsService.ReadComment] failed to read comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// ListCommentsOptions holds the paging of ListComments.
+type ListCommentsOptions struct {
+	// Limit and Offset select the page of comments returned.
+	Limit  int
+	Offset int
+}
+
+// ListComments attempts to list a page of the comments on the post with the
+// provided id, oldest first. The comments are returned along with the total
+// number of comments on the post, or an error, ErrNotFound if the post does
+// not exist.
+func (s *CommentsService) ListComments(
+	ctx context.Context,
+	postID uint64,
+	opts ListCommentsOptions,
+) ([]models.Comment, int, error) {
+	s.logger.DebugContext(ctx, "Listing comments", "post_id", postID, "limit", opts.Limit, "offset", opts.Offset)
+
+	if err := s.checkPost(ctx, postID); err != nil {
+		return nil, 0, fmt.Errorf("[in services.CommentsService.ListComments] %w", err)
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments WHERE post_id = $1`, postID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.CommentsService.ListComments] failed to count comments: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`
+		SELECT `+commentColumns+`
+		FROM comments
+		WHERE post_id = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+		`,
+		postID,
+		opts.Limit,
+		opts.Offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.CommentsService.ListComments] failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]models.Comment, 0, opts.Limit)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("[in services.CommentsService.ListComments] failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("[in services.CommentsService.ListComments] failed to read comments: %w", err)
+	}
+
+	return comments, total, nil
+}
+
+// DeleteComment attempts to delete the comment with the provided id from the
+// post with the provided id, returning an error, ErrNotFound if the post has
+// no such comment.
+func (s *CommentsService) DeleteComment(ctx context.Context, postID uint64, id uint64) error {
+	s.logger.DebugContext(ctx, "Deleting comment", "post_id", postID, "id", id)
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM comments WHERE id = $1 AND post_id = $2`, id, postID)
+	if err != nil {
+		return fmt.Errorf("[in services.CommentsService.DeleteComment] failed to delete comment: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[in services.CommentsService.DeleteComment] failed to read deleted rows: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("[in services.CommentsService.DeleteComment] comment %d on post %d: %w", id, postID, ErrNotFound)
+	}
+
+	return nil
+}
+
+// checkPost returns ErrNotFound if no post has the provided id.
+func (s *CommentsService) checkPost(ctx context.Context, postID uint64) error {
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM posts WHERE id = $1`, postID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("post %d: %w", postID, ErrNotFound)
+		}
+		return fmt.Errorf("failed to read post: %w", err)
+	}
+
+	return nil
+}
+
+// scanComment scans a row selected with commentColumns.
+func scanComment(row interface{ Scan(dest ...any) error }) (models.Comment, error) {
+	var comment models.Comment
+	err := row.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.AuthorID,
+		&comment.Message,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	return comment, err
+}
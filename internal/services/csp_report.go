@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// CSPReportsService is a service capable of storing and summarizing
+// models.CSPReport models.
+type CSPReportsService struct {
+	logger *slog.Logger
+	db     *database.DB
+}
+
+// NewCSPReportsService creates a new CSPReportsService and returns a pointer
+// to it.
+func NewCSPReportsService(logger *slog.Logger, db *database.DB) *CSPReportsService {
+	return &CSPReportsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreateCSPReports attempts to store the provided reports in a single
+// statement, returning an error if none could be stored.
+func (s *CSPReportsService) CreateCSPReports(ctx context.Context, reports []models.CSPReport) error {
+	s.logger.DebugContext(ctx, "Creating csp reports", "count", len(reports))
+
+	if len(reports) == 0 {
+		return nil
+	}
+
+	const columns = 7
+
+	var (
+		values = make([]string, len(reports))
+		args   = make([]any, 0, len(reports)*columns)
+	)
+
+	for i, report := range reports {
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+		values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+
+		var lineNumber any
+		if report.LineNumber > 0 {
+			lineNumber = report.LineNumber
+		}
+
+		args = append(
+			args,
+			report.DocumentURI,
+			report.BlockedURI,
+			report.EffectiveDirective,
+			report.Disposition,
+			nullString(report.SourceFile),
+			lineNumber,
+			s.db.Dialect.TimeArg(report.ReceivedAt),
+		)
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO csp_reports (document_uri, blocked_uri, effective_directive, disposition, source_file, line_number, received_at)
+		VALUES `+strings.Join(values, ", "),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.CSPReportsService.CreateCSPReports] failed to insert reports: %w", err)
+	}
+
+	return nil
+}
+
+// SummarizeCSPReports attempts to count the reports received since the
+// provided time by directive and blocked URI, returning up to limit of the
+// most frequent violations first.
+func (s *CSPReportsService) SummarizeCSPReports(
+	ctx context.Context,
+	since time.Time,
+	limit int,
+) ([]models.CSPReportSummary, error) {
+	s.logger.DebugContext(ctx, "Summarizing csp reports", "since", since)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`
+		SELECT effective_directive,
+		       blocked_uri,
+		       COUNT(*),
+		       MIN(received_at),
+		       MAX(received_at)
+		FROM csp_reports
+		WHERE received_at >= $1
+		GROUP BY effective_directive, blocked_uri
+		ORDER BY COUNT(*) DESC, effective_directive, blocked_uri
+		LIMIT $2
+		`,
+		s.db.Dialect.TimeArg(since),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[in services.CSPReportsService.SummarizeCSPReports] failed to query reports: %w",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	summaries := make([]models.CSPReportSummary, 0)
+	for rows.Next() {
+		var (
+			summary             models.CSPReportSummary
+			firstSeen, lastSeen database.Time
+		)
+
+		err = rows.Scan(&summary.EffectiveDirective, &summary.BlockedURI, &summary.Count, &firstSeen, &lastSeen)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[in services.CSPReportsService.SummarizeCSPReports] failed to scan summary: %w",
+				err,
+			)
+		}
+		summary.FirstSeen, summary.LastSeen = firstSeen.Time, lastSeen.Time
+
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(
+			"[in services.CSPReportsService.SummarizeCSPReports] failed to read summaries: %w",
+			err,
+		)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// How long the outcome of a feature detection is reused by HasFeature before
+// features are detected again. Detected features are redetected every few
+// minutes, so newly installed extensions are picked up without a restart. A
+// failed detection is retried sooner, but not on every call, so an outage
+// does not send every request to the database.
+const (
+	featuresTTL        = 5 * time.Minute
+	featuresFailureTTL = 10 * time.Second
+)
+
+// DependenciesService is a service capable of detecting the versions and
+// optional features of the application's dependencies, so features relying on
+// an extension can be turned off where it is not installed.
+type DependenciesService struct {
+	logger *slog.Logger
+	db     *database.DB
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	features  map[string]bool
+	expiresAt time.Time
+	// detecting is closed once the detection in progress finishes, and is nil
+	// when none is.
+	detecting chan struct{}
+}
+
+// NewDependenciesService creates a new DependenciesService and returns a
+// pointer to it.
+func NewDependenciesService(logger *slog.Logger, db *database.DB, clk clock.Clock) *DependenciesService {
+	return &DependenciesService{
+		logger: logger,
+		db:     db,
+		clock:  clk,
+	}
+}
+
+// ReadDependencies attempts to detect the database version, its installed
+// extensions and the optional features available, returning them or an
+// error.
+func (s *DependenciesService) ReadDependencies(ctx context.Context) (models.Dependencies, error) {
+	s.logger.DebugContext(ctx, "Reading dependencies")
+
+	info := models.DatabaseInfo{
+		Driver:     string(s.db.Dialect),
+		Extensions: []models.Extension{},
+	}
+	features := map[string]bool{
+		models.FeatureFullTextSearch: false,
+		models.FeatureTrigramSearch:  false,
+		models.FeatureVectorSearch:   false,
+	}
+
+	var versionQuery string
+	switch s.db.Dialect {
+	case database.DialectPostgres:
+		versionQuery = `SHOW server_version`
+	case database.DialectMySQL:
+		versionQuery = `SELECT VERSION()`
+	case database.DialectSQLite:
+		versionQuery = `SELECT sqlite_version()`
+	}
+	if err := s.db.QueryRowContext(ctx, versionQuery).Scan(&info.Version); err != nil {
+		return models.Dependencies{}, fmt.Errorf(
+			"[in services.DependenciesService.ReadDependencies] failed to read database version: %w",
+			err,
+		)
+	}
+
+	switch s.db.Dialect {
+	case database.DialectPostgres:
+		// Text search is built in, other features come from extensions.
+		extensions, err := s.listExtensions(ctx)
+		if err != nil {
+			return models.Dependencies{}, fmt.Errorf("[in services.DependenciesService.ReadDependencies] %w", err)
+		}
+		info.Extensions = extensions
+
+		features[models.FeatureFullTextSearch] = true
+		for _, extension := range extensions {
+			switch extension.Name {
+			case models.FeatureTrigramSearch, models.FeatureVectorSearch:
+				features[extension.Name] = true
+			}
+		}
+	case database.DialectMySQL:
+		// InnoDB supports FULLTEXT indexes in every supported MySQL version.
+		features[models.FeatureFullTextSearch] = true
+	case database.DialectSQLite:
+		// Full-text search depends on how SQLite was compiled.
+		var one int
+		err := s.db.QueryRowContext(
+			ctx,
+			`SELECT 1 FROM pragma_compile_options WHERE compile_options = 'ENABLE_FTS5'`,
+		).Scan(&one)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return models.Dependencies{}, fmt.Errorf(
+				"[in services.DependenciesService.ReadDependencies] failed to read compile options: %w",
+				err,
+			)
+		}
+		features[models.FeatureFullTextSearch] = err == nil
+	}
+
+	return models.Dependencies{
+		Database: info,
+		Features: features,
+	}, nil
+}
+
+// HasFeature reports whether the optional feature with the provided name is
+// available. Detected features are reused for a few minutes, and concurrent
+// callers share a single detection. A failed detection is logged and reported
+// as unavailable, so callers fall back to what every database supports.
+func (s *DependenciesService) HasFeature(ctx context.Context, feature string) bool {
+	s.mu.Lock()
+	if s.clock.Now().Before(s.expiresAt) {
+		available := s.features[feature]
+		s.mu.Unlock()
+		return available
+	}
+
+	done := s.detecting
+	if done == nil {
+		done = make(chan struct{})
+		s.detecting = done
+		// The detection outlives the request that started it, as other callers
+		// wait for it too.
+		go s.detectFeatures(context.WithoutCancel(ctx), done)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.features[feature]
+}
+
+// detectFeatures detects the optional features available and stores them for
+// HasFeature, closing done once they are stored.
+func (s *DependenciesService) detectFeatures(ctx context.Context, done chan struct{}) {
+	dependencies, err := s.ReadDependencies(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer close(done)
+
+	s.detecting = nil
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to detect features", slog.String("error", err.Error()))
+
+		s.features = nil
+		s.expiresAt = s.clock.Now().Add(featuresFailureTTL)
+		return
+	}
+
+	s.features = dependencies.Features
+	s.expiresAt = s.clock.Now().Add(featuresTTL)
+}
+
+// listExtensions lists the extensions installed in the Postgres database.
+func (s *DependenciesService) listExtensions(ctx context.Context) ([]models.Extension, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT extname, extversion FROM pg_extension ORDER BY extname`)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.DependenciesService.listExtensions] failed to list extensions: %w", err)
+	}
+	defer rows.Close()
+
+	extensions := []models.Extension{}
+	for rows.Next() {
+		var extension models.Extension
+		if err := rows.Scan(&extension.Name, &extension.Version); err != nil {
+			return nil, fmt.Errorf("[in services.DependenciesService.listExtensions] failed to scan extension: %w", err)
+		}
+		extensions = append(extensions, extension)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.DependenciesService.listExtensions] failed to list extensions: %w", err)
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// EventsService is a service capable of storing client-side analytics
+// models.Event models.
+type EventsService struct {
+	logger    *slog.Logger
+	db        *database.DB
+	anonymize bool
+}
+
+// NewEventsService creates a new EventsService and returns a pointer to it.
+// When anonymize is set, client addresses are truncated and user agents are
+// dropped before events are stored.
+func NewEventsService(logger *slog.Logger, db *database.DB, anonymize bool) *EventsService {
+	return &EventsService{
+		logger:    logger,
+		db:        db,
+		anonymize: anonymize,
+	}
+}
+
+// CreateEvents attempts to store the provided events in a single statement,
+// returning an error if none could be stored.
+func (s *EventsService) CreateEvents(ctx context.Context, events []models.Event) error {
+	s.logger.DebugContext(ctx, "Creating events", "count", len(events))
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	const columns = 8
+
+	var (
+		values = make([]string, len(events))
+		args   = make([]any, 0, len(events)*columns)
+	)
+
+	for i, event := range events {
+		if s.anonymize {
+			event.ClientIP = truncateIP(event.ClientIP)
+			event.UserAgent = ""
+		}
+
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*columns+j+1)
+		}
+		values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+
+		args = append(
+			args,
+			event.Type,
+			event.Path,
+			event.SessionID,
+			event.Value,
+			nullString(event.ClientIP),
+			nullString(event.UserAgent),
+			event.OccurredAt,
+			event.ReceivedAt,
+		)
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO events (type, path, session_id, value, client_ip, user_agent, occurred_at, received_at)
+		VALUES `+strings.Join(values, ", "),
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.EventsService.CreateEvents] failed to insert events: %w", err)
+	}
+
+	return nil
+}
+
+// truncateIP zeroes the host part of an address, keeping the /24 of IPv4 and
+// the /48 of IPv6 addresses, which is enough for coarse geo lookups but no
+// longer identifies a client.
+func truncateIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
+
+// nullString converts an empty string into a SQL NULL.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// ErrAuthorNotFound is returned when a post is created for a user that does
+// not exist.
+var ErrAuthorNotFound = errors.New("author not found")
+
+// postColumns are the columns read into a models.Post by scanPost. Posts that
+// were never summarized have a NULL summary, read as an empty one.
+const postColumns = `id, author_id, title, body, COALESCE(summary, ''), created_at, updated_at`
+
+// PostsService is a service capable of performing CRUD operations for
+// models.Post models.
+type PostsService struct {
+	logger *slog.Logger
+	db     *database.DB
+}
+
+// NewPostsService creates a new PostsService and returns a pointer to it.
+func NewPostsService(logger *slog.Logger, db *database.DB) *PostsService {
+	return &PostsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreatePost attempts to create the provided post, returning a fully hydrated
+// models.Post or an error, ErrAuthorNotFound if the author does not exist.
+func (s *PostsService) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	s.logger.DebugContext(ctx, "Creating post", "author_id", post.AuthorID)
+
+	// The author is checked up front so a missing one is reported the same way
+	// by every driver, rather than as a driver specific constraint error.
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM users WHERE id = $1`, post.AuthorID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, fmt.Errorf(
+				"[in services.PostsService.CreatePost] user %d: %w",
+				post.AuthorID,
+				ErrAuthorNotFound,
+			)
+		}
+		return models.Post{}, fmt.Errorf("[in services.PostsService.CreatePost] failed to read author: %w", err)
+	}
+
+	id, err := s.db.InsertReturningID(
+		ctx,
+		`
+		INSERT INTO posts (author_id, title, body)
+		VALUES ($1, $2, $3)
+		`,
+		post.AuthorID,
+		post.Title,
+		post.Body,
+	)
+	if err != nil {
+		return models.Post{}, fmt.Errorf("[in services.PostsService.CreatePost] failed to create post: %w", err)
+	}
+
+	// Read the post back to hydrate the columns set by the database.
+	created, err := s.ReadPost(ctx, uint64(id))
+	if err != nil {
+		return models.Post{}, fmt.Errorf("[in services.PostsService.CreatePost] %w", err)
+	}
+
+	return created, nil
+}
+
+// ReadPost attempts to read the post with the provided id, returning it or an
+// error, ErrNotFound if no post has the id.
+func (s *PostsService) ReadPost(ctx context.Context, id uint64) (models.Post, error) {
+	s.logger.DebugContext(ctx, "Reading post", "id", id)
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1`, id)
+
+	post, err := scanPost(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, fmt.Errorf("[in services.PostsService.ReadPost] post %d: %w", id, ErrNotFound)
+		}
+		return models.Post{}, fmt.Errorf("[in services.PostsService.ReadPost] failed to read post: %w", err)
+	}
+
+	return post, nil
+}
+
+// UpdatePost attempts to replace the title and body of the post with the
+// provided id, clearing its summary. The author of a post never changes. The
+// updated models.Post or an error is returned, ErrNotFound if no post has the
+// id.
+func (s *PostsService) UpdatePost(ctx context.Context, id uint64, patch models.Post) (models.Post, error) {
+	s.logger.DebugContext(ctx, "Updating post", "id", id)
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`
+		UPDATE posts
+		SET title = $1,
+		    body = $2,
+		    summary = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		`,
+		patch.Title,
+		patch.Body,
+		id,
+	)
+	if err != nil {
+		return models.Post{}, fmt.Errorf("[in services.PostsService.UpdatePost] failed to update post: %w", err)
+	}
+
+	// MySQL reports no affected rows when nothing changed, so whether the post
+	// exists is checked by reading it back.
+	updated, err := s.ReadPost(ctx, id)
+	if err != nil {
+		return models.Post{}, fmt.Errorf("[in services.PostsService.UpdatePost] %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeletePost attempts to delete the post with the provided id, returning an
+// error, ErrNotFound if no post has the id.
+func (s *PostsService) DeletePost(ctx context.Context, id uint64) error {
+	s.logger.DebugContext(ctx, "Deleting post", "id", id)
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM posts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("[in services.PostsService.DeletePost] failed to delete post: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[in services.PostsService.DeletePost] failed to read deleted rows: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("[in services.PostsService.DeletePost] post %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListPostsOptions holds the paging and filtering of ListPosts.
+type ListPostsOptions struct {
+	// Limit and Offset select the page of posts returned.
+	Limit  int
+	Offset int
+	// AuthorID only keeps posts written by the user with the id. Zero is
+	// ignored.
+	AuthorID uint64
+}
+
+// ListPosts attempts to list a page of posts matching the provided options,
+// newest first. The posts are returned along with the total number of posts
+// matching the filters, or an error.
+func (s *PostsService) ListPosts(ctx context.Context, opts ListPostsOptions) ([]models.Post, int, error) {
+	s.logger.DebugContext(ctx, "Listing posts", "limit", opts.Limit, "offset", opts.Offset, "author_id", opts.AuthorID)
+
+	var (
+		where string
+		args  []any
+	)
+	if opts.AuthorID != 0 {
+		args = append(args, opts.AuthorID)
+		where = "WHERE author_id = $1"
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM posts `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.PostsService.ListPosts] failed to count posts: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`
+			SELECT %s
+			FROM posts
+			%s
+			ORDER BY created_at DESC, id DESC
+			LIMIT $%d OFFSET $%d
+			`,
+			postColumns, where, len(args)+1, len(args)+2,
+		),
+		append(args, opts.Limit, opts.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.PostsService.ListPosts] failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.Post, 0, opts.Limit)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("[in services.PostsService.ListPosts] failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("[in services.PostsService.ListPosts] failed to read posts: %w", err)
+	}
+
+	return posts, total, nil
+}
+
+// SetPostEmbedding attempts to store the embedding of the post with the
+// provided id, replacing any previous one. An error is returned, ErrNotFound if
+// no post has the id.
+func (s *PostsService) SetPostEmbedding(ctx context.Context, id uint64, embedding []float32) error {
+	s.logger.DebugContext(ctx, "Setting post embedding", "id", id, "dimensions", len(embedding))
+
+	if _, err := s.ReadPost(ctx, id); err != nil {
+		return fmt.Errorf("[in services.PostsService.SetPostEmbedding] %w", err)
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO post_embeddings (post_id, embedding, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		`+s.db.Dialect.Upsert([]string{"post_id"}, []string{"embedding", "updated_at"}),
+		id,
+		formatVector(embedding),
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.PostsService.SetPostEmbedding] failed to save embedding: %w", err)
+	}
+
+	return nil
+}
+
+// ListPostsNeedingEmbedding attempts to list up to limit posts without an
+// embedding, or updated since they were embedded, oldest first. The posts are
+// returned or an error.
+func (s *PostsService) ListPostsNeedingEmbedding(ctx context.Context, limit int) ([]models.Post, error) {
+	s.logger.DebugContext(ctx, "Listing posts needing embedding", "limit", limit)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`
+		SELECT `+postColumns+`
+		FROM posts
+		WHERE NOT EXISTS (
+		    SELECT 1
+		    FROM post_embeddings e
+		    WHERE e.post_id = posts.id
+		      AND e.updated_at >= posts.updated_at
+		)
+		ORDER BY id
+		LIMIT $1
+		`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("[in services.PostsService.ListPostsNeedingEmbedding] failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.Post, 0, limit)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("[in services.PostsService.ListPostsNeedingEmbedding] failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("[in services.PostsService.ListPostsNeedingEmbedding] failed to read posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+// SearchPosts attempts to find up to limit posts matching the provided query,
+// best matches first. Posts are ranked by the cosine distance of their
+// embedding to the query's when embedding is set, which requires the vector
+// extension, and otherwise by the best matching the database supports. The
+// posts are returned along with the search mode used, SearchModeVector,
+// SearchModeFullText or SearchModeSubstring, or an error.
+func (s *PostsService) SearchPosts(ctx context.Context, query string, embedding []float32, limit int) ([]models.Post, string, error) {
+	s.logger.DebugContext(ctx, "Searching posts", "query", query, "limit", limit, "embedding", embedding != nil)
+
+	var (
+		mode string
+		stmt string
+		args []any
+	)
+	switch {
+	case embedding != nil:
+		mode = SearchModeVector
+		stmt = `
+			SELECT ` + postColumns + `
+			FROM posts
+			JOIN (
+			    SELECT post_id, embedding::vector <=> $1::vector AS distance
+			    FROM post_embeddings
+			    WHERE vector_dims(embedding::vector) = vector_dims($1::vector)
+			) matched ON matched.post_id = posts.id
+			ORDER BY matched.distance, id
+			LIMIT $2
+			`
+		args = []any{formatVector(embedding), limit}
+	case s.db.Dialect == database.DialectPostgres:
+		mode = SearchModeFullText
+		stmt = `
+			SELECT ` + postColumns + `
+			FROM posts
+			WHERE to_tsvector('english', title || ' ' || body) @@ websearch_to_tsquery('english', $1)
+			ORDER BY ts_rank(to_tsvector('english', title || ' ' || body), websearch_to_tsquery('english', $1)) DESC, id
+			LIMIT $2
+			`
+		args = []any{query, limit}
+	case s.db.Dialect == database.DialectMySQL:
+		mode = SearchModeFullText
+		stmt = `
+			SELECT ` + postColumns + `
+			FROM posts
+			WHERE MATCH (title, body) AGAINST ($1 IN NATURAL LANGUAGE MODE)
+			ORDER BY MATCH (title, body) AGAINST ($1 IN NATURAL LANGUAGE MODE) DESC, id
+			LIMIT $2
+			`
+		args = []any{query, limit}
+	default:
+		mode = SearchModeSubstring
+		stmt = `
+			SELECT ` + postColumns + `
+			FROM posts
+			WHERE LOWER(title) LIKE $1 ESCAPE '!'
+			   OR LOWER(body) LIKE $1 ESCAPE '!'
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+			`
+		args = []any{"%" + escapeLike(strings.ToLower(query)) + "%", limit}
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("[in services.PostsService.SearchPosts] failed to search posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.Post, 0, limit)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("[in services.PostsService.SearchPosts] failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("[in services.PostsService.SearchPosts] failed to read posts: %w", err)
+	}
+
+	return posts, mode, nil
+}
+
+// Ways ListRelatedPosts can relate posts, from best to most basic.
+const (
+	// RelatedModeVector ranks posts by the cosine distance of their embedding
+	// to the post's. Requires the vector extension and an embedding for the
+	// post.
+	RelatedModeVector = "vector"
+	// RelatedModeFullText ranks posts by how well they match the words in the
+	// post's title. Requires Postgres or MySQL.
+	RelatedModeFullText = "full_text"
+	// RelatedModeAuthor lists other posts by the same author, newest first.
+	RelatedModeAuthor = "author"
+)
+
+// ListRelatedPosts attempts to list up to limit posts related to the post with
+// the provided id, most related first. Embeddings are compared when vector is
+// set, which requires the vector extension, and otherwise the best matching
+// the database supports. The posts are returned along with the mode used, or
+// an error, ErrNotFound if no post has the id.
+func (s *PostsService) ListRelatedPosts(ctx context.Context, id uint64, limit int, vector bool) ([]models.Post, string, error) {
+	s.logger.DebugContext(ctx, "Listing related posts", "id", id, "limit", limit, "vector", vector)
+
+	post, err := s.ReadPost(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("[in services.PostsService.ListRelatedPosts] %w", err)
+	}
+
+	var embedding string
+	if vector {
+		err = s.db.QueryRowContext(ctx, `SELECT embedding FROM post_embeddings WHERE post_id = $1`, id).Scan(&embedding)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, "", fmt.Errorf("[in services.PostsService.ListRelatedPosts] failed to read embedding: %w", err)
+		}
+	}
+
+	var (
+		mode  string
+		query string
+		args  []any
+	)
+	switch {
+	case embedding != "":
+		// Only embeddings of the same size can be compared.
+		mode = RelatedModeVector
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts
+			JOIN (
+			    SELECT post_id, embedding::vector <=> $2::vector AS distance
+			    FROM post_embeddings
+			    WHERE post_id <> $1
+			      AND vector_dims(embedding::vector) = vector_dims($2::vector)
+			) related ON related.post_id = posts.id
+			ORDER BY related.distance, id
+			LIMIT $3
+			`
+		args = []any{id, embedding, limit}
+	case s.db.Dialect == database.DialectPostgres:
+		// Any of the title's words may match, so a single shared word is
+		// enough to relate two posts.
+		mode = RelatedModeFullText
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts, (
+			    SELECT to_tsquery('simple', array_to_string(tsvector_to_array(to_tsvector('english', $2)), ' | ')) AS query
+			) related
+			WHERE id <> $1
+			  AND to_tsvector('english', title || ' ' || body) @@ related.query
+			ORDER BY ts_rank(to_tsvector('english', title || ' ' || body), related.query) DESC, id
+			LIMIT $3
+			`
+		args = []any{id, post.Title, limit}
+	case s.db.Dialect == database.DialectMySQL:
+		mode = RelatedModeFullText
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts
+			WHERE id <> $1
+			  AND MATCH (title, body) AGAINST ($2 IN NATURAL LANGUAGE MODE)
+			ORDER BY MATCH (title, body) AGAINST ($2 IN NATURAL LANGUAGE MODE) DESC, id
+			LIMIT $3
+			`
+		args = []any{id, post.Title, limit}
+	default:
+		mode = RelatedModeAuthor
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts
+			WHERE author_id = $1
+			  AND id <> $2
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3
+			`
+		args = []any{post.AuthorID, id, limit}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("[in services.PostsService.ListRelatedPosts] failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.Post, 0, limit)
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("[in services.PostsService.ListRelatedPosts] failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("[in services.PostsService.ListRelatedPosts] failed to read posts: %w", err)
+	}
+
+	return posts, mode, nil
+}
+
+// formatVector formats v in pgvector's text format, e.g. "[0.1,0.2]".
+func formatVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// scanPost scans a row selected with postColumns.
+func scanPost(row interface{ Scan(dest ...any) error }) (models.Post, error) {
+	var post models.Post
+	err := row.Scan(&post.ID, &post.AuthorID, &post.Title, &post.Body, &post.Summary, &post.CreatedAt, &post.UpdatedAt)
+	return post, err
+}
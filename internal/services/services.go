@@ -0,0 +1,16 @@
+package services
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidCredentials is returned when an email address and password do
+	// not match a user.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrEmailTaken is returned when another user already has the email
+	// address a user is created or updated with.
+	ErrEmailTaken = errors.New("email address taken")
+)
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+// settingsData is the JSON document stored in the settings table.
+type settingsData struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Theme       map[string]string `json:"theme"`
+	SocialLinks map[string]string `json:"social_links"`
+}
+
+// SettingsService is a service capable of reading and replacing the blog-level
+// models.Settings. Settings are read on most page renders, so they are cached
+// in memory and the cache is replaced whenever they are updated through this
+// service. Other instances keep their cached copy until they restart.
+type SettingsService struct {
+	logger *slog.Logger
+	db     *database.DB
+
+	mu     sync.RWMutex
+	cached *models.Settings
+}
+
+// NewSettingsService creates a new SettingsService and returns a pointer to it.
+func NewSettingsService(logger *slog.Logger, db *database.DB) *SettingsService {
+	return &SettingsService{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// ReadSettings attempts to read the blog settings, returning empty settings if
+// none have been saved yet.
+func (s *SettingsService) ReadSettings(ctx context.Context) (models.Settings, error) {
+	s.mu.RLock()
+	cached := s.cached
+	s.mu.RUnlock()
+
+	if cached != nil {
+		return *cached, nil
+	}
+
+	s.logger.DebugContext(ctx, "Reading settings")
+
+	var (
+		data     []byte
+		settings models.Settings
+	)
+
+	err := s.db.QueryRowContext(ctx, `SELECT data, updated_at FROM settings WHERE id = 1`).Scan(&data, &settings.UpdatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Nothing saved yet, empty settings are cached like any others.
+	case err != nil:
+		return models.Settings{}, fmt.Errorf("[in services.SettingsService.ReadSettings] failed to read settings: %w", err)
+	default:
+		var stored settingsData
+		if err = json.Unmarshal(data, &stored); err != nil {
+			return models.Settings{}, fmt.Errorf("[in services.SettingsService.ReadSettings] failed to decode settings: %w", err)
+		}
+
+		settings.Title = stored.Title
+		settings.Description = stored.Description
+		settings.Theme = stored.Theme
+		settings.SocialLinks = stored.SocialLinks
+	}
+
+	s.mu.Lock()
+	s.cached = &settings
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+// UpdateSettings attempts to replace the blog settings, returning the saved
+// models.Settings or an error.
+func (s *SettingsService) UpdateSettings(ctx context.Context, settings models.Settings) (models.Settings, error) {
+	s.logger.DebugContext(ctx, "Updating settings")
+
+	data, err := json.Marshal(settingsData{
+		Title:       settings.Title,
+		Description: settings.Description,
+		Theme:       settings.Theme,
+		SocialLinks: settings.SocialLinks,
+	})
+	if err != nil {
+		return models.Settings{}, fmt.Errorf("[in services.SettingsService.UpdateSettings] failed to encode settings: %w", err)
+	}
+
+	// Clear the cache before writing, so a failed write can't leave stale
+	// settings behind.
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+
+	_, err = s.db.ExecContext(
+		ctx,
+		`
+		INSERT INTO settings (id, data, updated_at)
+		VALUES (1, $1, CURRENT_TIMESTAMP)
+		`+s.db.Dialect.Upsert([]string{"id"}, []string{"data", "updated_at"}),
+		string(data),
+	)
+	if err != nil {
+		return models.Settings{}, fmt.Errorf("[in services.SettingsService.UpdateSettings] failed to save settings: %w", err)
+	}
+
+	// Reading back fills the cache with the stored settings.
+	saved, err := s.ReadSettings(ctx)
+	if err != nil {
+		return models.Settings{}, fmt.Errorf("[in services.SettingsService.UpdateSettings] %w", err)
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jha-captech/blog/internal/database"
+	"github.com/jha-captech/blog/internal/llm"
+	"github.com/jha-captech/blog/internal/models"
+)
+
+var (
+	// ErrSummariesDisabled is returned when a summary has to be generated but
+	// no language model is configured.
+	ErrSummariesDisabled = errors.New("summaries are disabled")
+
+	// ErrSummaryFailed is returned when the language model could not generate
+	// a summary.
+	ErrSummaryFailed = errors.New("summary generation failed")
+)
+
+// summaryInstructions are the instructions the language model summarizes posts
+// with.
+const summaryInstructions = `You write the meta descriptions of blog posts.
+Summarize the post you are given in one or two plain sentences of at most 160
+characters, in the language of the post. Reply with the summary only, without
+quotes, markdown or any introduction.`
+
+// maxSummaryLength is the longest summary stored, in characters. Longer
+// replies are cut at the last word that fits.
+const maxSummaryLength = 300
+
+// SummariesService is a service capable of summarizing posts with a language
+// model, caching the summaries by the content they summarize.
+type SummariesService struct {
+	logger   *slog.Logger
+	db       *database.DB
+	provider llm.Provider
+}
+
+// NewSummariesService creates a new SummariesService and returns a pointer to
+// it. provider may be nil, in which case only cached summaries are available.
+func NewSummariesService(logger *slog.Logger, db *database.DB, provider llm.Provider) *SummariesService {
+	return &SummariesService{
+		logger:   logger,
+		db:       db,
+		provider: provider,
+	}
+}
+
+// SummarizePost attempts to summarize the provided post and store the summary
+// on it. Posts whose title and body were summarized before reuse that summary
+// rather than calling the language model again. The post with its summary is
+// returned along with whether the summary came from the cache, or an error,
+// ErrSummariesDisabled or ErrSummaryFailed when no summary could be generated
+// and ErrNotFound if the post no longer exists.
+func (s *SummariesService) SummarizePost(ctx context.Context, post models.Post) (models.Post, bool, error) {
+	s.logger.DebugContext(ctx, "Summarizing post", "id", post.ID)
+
+	hash := contentHash(post.Title, post.Body)
+
+	var summary string
+	err := s.db.QueryRowContext(ctx, `SELECT summary FROM summaries WHERE content_hash = $1`, hash).Scan(&summary)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] failed to read cached summary: %w", err)
+	}
+	cached := err == nil
+
+	if !cached {
+		if summary, err = s.generate(ctx, post); err != nil {
+			return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] %w", err)
+		}
+
+		_, err = s.db.ExecContext(
+			ctx,
+			`
+			INSERT INTO summaries (content_hash, summary)
+			VALUES ($1, $2)
+			`+s.db.Dialect.Upsert([]string{"content_hash"}, []string{"summary"}),
+			hash,
+			summary,
+		)
+		if err != nil {
+			return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] failed to cache summary: %w", err)
+		}
+	}
+
+	// The summary is stored without touching updated_at, as the content it
+	// describes has not changed.
+	_, err = s.db.ExecContext(ctx, `UPDATE posts SET summary = $1 WHERE id = $2`, summary, post.ID)
+	if err != nil {
+		return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] failed to save summary: %w", err)
+	}
+
+	row := s.db.QueryRowContext(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1`, post.ID)
+	summarized, err := scanPost(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] post %d: %w", post.ID, ErrNotFound)
+		}
+		return models.Post{}, false, fmt.Errorf("[in services.SummariesService.SummarizePost] failed to read post: %w", err)
+	}
+
+	return summarized, cached, nil
+}
+
+// generate asks the language model for a summary of post, tidied into a
+// single line of at most maxSummaryLength characters.
+func (s *SummariesService) generate(ctx context.Context, post models.Post) (string, error) {
+	if s.provider == nil {
+		return "", ErrSummariesDisabled
+	}
+
+	reply, err := s.provider.Complete(ctx, summaryInstructions, post.Title+"\n\n"+post.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSummaryFailed, err)
+	}
+
+	summary := strings.Trim(strings.Join(strings.Fields(reply), " "), `"'`)
+	if summary == "" {
+		return "", fmt.Errorf("%w: language model returned an empty summary", ErrSummaryFailed)
+	}
+
+	if utf8.RuneCountInString(summary) > maxSummaryLength {
+		runes := []rune(summary)[:maxSummaryLength-1]
+		summary = string(runes)
+		if i := strings.LastIndexByte(summary, ' '); i > 0 {
+			summary = summary[:i]
+		}
+		summary += "…"
+	}
+
+	return summary, nil
+}
+
+// contentHash returns the hex encoded SHA-256 hash summaries of the provided
+// title and body are cached under.
+func contentHash(title string, body string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + body))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,82 +6,783 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"unicode"
 
+	"github.com/jha-captech/blog/internal/crypto"
+	"github.com/jha-captech/blog/internal/database"
 	"github.com/jha-captech/blog/internal/models"
+	"github.com/jha-captech/blog/internal/password"
 )
 
 // UsersService is a service capable of performing CRUD operations for
 // models.User models.
+// Emails are stored encrypted, along with a blind index of the lowercased
+// address to find users by email.
 type UsersService struct {
-	logger *slog.Logger
-	db     *sql.DB
+	logger    *slog.Logger
+	db        *database.DB
+	passwords *password.Hasher
+	keyring   *crypto.Keyring
 }
 
-// NewUsersService creates a new UsersService and returns a pointer to it.
-func NewUsersService(logger *slog.Logger, db *sql.DB) *UsersService {
+// NewUsersService creates a new UsersService storing passwords hashed with the
+// provided hasher and emails encrypted with the provided keyring, and returns a
+// pointer to it.
+func NewUsersService(
+	logger *slog.Logger,
+	db *database.DB,
+	passwords *password.Hasher,
+	keyring *crypto.Keyring,
+) *UsersService {
 	return &UsersService{
-		logger: logger,
-		db:     db,
+		logger:    logger,
+		db:        db,
+		passwords: passwords,
+		keyring:   keyring,
 	}
 }
 
-// CreateUser attempts to create the provided user, returning a fully hydrated
-// models.User or an error.
-func (s *UsersService) CreateUser(ctx context.Context, user models.User) (models.User, error) {
-	return models.User{}, nil
+// CreateUser attempts to create the provided user with the provided password,
+// which is stored hashed. A fully hydrated models.User or an error is
+// returned, ErrEmailTaken if another user has the email address.
+func (s *UsersService) CreateUser(ctx context.Context, user models.User, password string) (models.User, error) {
+	var created models.User
+	err := s.db.InTx(ctx, func(tx *database.Tx) error {
+		var err error
+		created, err = s.createUser(ctx, tx, user, password)
+		return err
+	})
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.CreateUser] %w", err)
+	}
+
+	return created, nil
+}
+
+// createUser creates a user as described by CreateUser, running its queries
+// with q, which must be a transaction as the change is recorded separately.
+func (s *UsersService) createUser(ctx context.Context, q database.Querier, user models.User, password string) (models.User, error) {
+	s.logger.DebugContext(ctx, "Creating user", "email_index", s.emailIndex(user.Email))
+
+	// Seeded users have no blind index for the unique constraint to catch, so
+	// their emails are checked for first.
+	taken, err := s.emailTaken(ctx, q, user.Email)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", err)
+	}
+	if taken {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", ErrEmailTaken)
+	}
+
+	hash, err := s.passwords.Hash(password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", err)
+	}
+
+	email, err := s.keyring.Encrypt(user.Email)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", err)
+	}
+
+	id, err := q.InsertReturningID(
+		ctx,
+		`
+		INSERT INTO users (name, email, email_index, password_hash)
+		VALUES ($1, $2, $3, $4)
+		`,
+		user.Name,
+		email,
+		s.emailIndex(user.Email),
+		hash,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", ErrEmailTaken)
+		}
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.createUser] failed to create user: %w",
+			err,
+		)
+	}
+
+	if err = s.recordUserChange(ctx, q, uint64(id), false); err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", err)
+	}
+
+	// Read the user back to hydrate the columns set by the database.
+	created, err := s.readUser(ctx, q, uint64(id))
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.createUser] %w", err)
+	}
+
+	return created, nil
+}
+
+// VerifyPassword attempts to find the user with the provided email address
+// and password, returning it or an error, ErrInvalidCredentials if no user
+// matches.
+func (s *UsersService) VerifyPassword(ctx context.Context, email string, password string) (models.User, error) {
+	s.logger.DebugContext(ctx, "Verifying password", "email_index", s.emailIndex(email))
+
+	row := s.db.QueryRowContext(
+		ctx,
+		`
+		SELECT id,
+		       name,
+		       email,
+		       password_hash,
+		       created_at,
+		       updated_at
+		FROM users
+		WHERE email_index = $1
+		   OR (email_index IS NULL AND LOWER(email) = $2)
+		ORDER BY id
+		LIMIT 1
+		`,
+		s.emailIndex(email),
+		strings.ToLower(email),
+	)
+
+	var user models.User
+
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, fmt.Errorf(
+			"[in services.UsersService.VerifyPassword] failed to read user: %w",
+			err,
+		)
+	}
+	if user.Email, err = s.keyring.Decrypt(user.Email); err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.VerifyPassword] %w", err)
+	}
+
+	// An unknown email leaves the hash empty, which is still checked so that
+	// response times do not reveal which emails have an account.
+	ok, err := s.passwords.Verify(user.PasswordHash, password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.VerifyPassword] %w", err)
+	}
+	if !ok {
+		return models.User{}, fmt.Errorf("[in services.UsersService.VerifyPassword] %w", ErrInvalidCredentials)
+	}
+
+	return user, nil
+}
+
+// emailTaken reports whether a user has the provided email address, running
+// its query with q so it can take part in a transaction.
+func (s *UsersService) emailTaken(ctx context.Context, q database.Querier, email string) (bool, error) {
+	var one int
+	err := q.QueryRowContext(
+		ctx,
+		`
+		SELECT 1
+		FROM users
+		WHERE email_index = $1
+		   OR (email_index IS NULL AND LOWER(email) = $2)
+		LIMIT 1
+		`,
+		s.emailIndex(email),
+		strings.ToLower(email),
+	).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("[in services.UsersService.emailTaken] failed to check email: %w", err)
+	}
+
+	return true, nil
 }
 
 // ReadUser attempts to read a user from the database using the provided id. A
-// fully hydrated models.User or error is returned.
+// fully hydrated models.User or error is returned, ErrNotFound if no user has
+// the id.
 func (s *UsersService) ReadUser(ctx context.Context, id uint64) (models.User, error) {
+	return s.readUser(ctx, s.db, id)
+}
+
+// readUser reads a user as described by ReadUser, running its query with q so
+// it can take part in a transaction.
+func (s *UsersService) readUser(ctx context.Context, q database.Querier, id uint64) (models.User, error) {
 	s.logger.DebugContext(ctx, "Reading user", "id", id)
 
-	row := s.db.QueryRowContext(
+	row := q.QueryRowContext(
 		ctx,
 		`
 		SELECT id,
 		       name,
 		       email,
-		       password
+		       password_hash,
+		       created_at,
+		       updated_at
 		FROM users
-		WHERE id = $1::int
+		WHERE id = $1
 		`,
 		id,
 	)
 
 	var user models.User
 
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.Password)
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return models.User{}, nil
+			return models.User{}, fmt.Errorf("[in services.UsersService.readUser] user %d: %w", id, ErrNotFound)
 		default:
 			return models.User{}, fmt.Errorf(
-				"[in services.UsersService.ReadUser] failed to read user: %w",
+				"[in services.UsersService.readUser] failed to read user: %w",
 				err,
 			)
 		}
 	}
+	if user.Email, err = s.keyring.Decrypt(user.Email); err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.readUser] %w", err)
+	}
 
 	return user, nil
 }
 
+// ListUsersChangedSince attempts to list up to limit users changed after the
+// change numbered since, ordered by their latest change. Users changed several
+// times are only listed once, with their latest change, and deleted users are
+// listed as deleted changes without their details.
+func (s *UsersService) ListUsersChangedSince(ctx context.Context, since uint64, limit int) ([]models.UserChange, error) {
+	s.logger.DebugContext(ctx, "Listing users changed since", "since", since)
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`
+		SELECT c.seq,
+		       c.user_id,
+		       c.deleted,
+		       u.name,
+		       u.email,
+		       u.password_hash,
+		       u.created_at,
+		       u.updated_at
+		FROM user_changes c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.seq > $1
+		  AND c.seq = (SELECT MAX(seq) FROM user_changes WHERE user_id = c.user_id)
+		ORDER BY c.seq
+		LIMIT $2
+		`,
+		since,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"[in services.UsersService.ListUsersChangedSince] failed to query user changes: %w",
+			err,
+		)
+	}
+	defer rows.Close()
+
+	changes := make([]models.UserChange, 0, limit)
+	for rows.Next() {
+		var (
+			change                    models.UserChange
+			name, email, passwordHash sql.NullString
+			createdAt, updatedAt      sql.NullTime
+		)
+
+		err = rows.Scan(
+			&change.Seq,
+			&change.UserID,
+			&change.Deleted,
+			&name,
+			&email,
+			&passwordHash,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"[in services.UsersService.ListUsersChangedSince] failed to scan user change: %w",
+				err,
+			)
+		}
+
+		// Users are deleted in the same transaction as their deleted change is
+		// recorded, so a missing user is only seen alongside one.
+		if change.Deleted || !name.Valid {
+			change.Deleted = true
+			changes = append(changes, change)
+			continue
+		}
+
+		change.User = models.User{
+			ID:           change.UserID,
+			Name:         name.String,
+			PasswordHash: passwordHash.String,
+			CreatedAt:    createdAt.Time,
+			UpdatedAt:    updatedAt.Time,
+		}
+		if change.User.Email, err = s.keyring.Decrypt(email.String); err != nil {
+			return nil, fmt.Errorf("[in services.UsersService.ListUsersChangedSince] %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(
+			"[in services.UsersService.ListUsersChangedSince] failed to read user changes: %w",
+			err,
+		)
+	}
+
+	return changes, nil
+}
+
+// recordUserChange records a change to the user with the provided id for
+// ListUsersChangedSince. It runs its query with q, which must be the
+// transaction making the change so the two are committed together.
+func (s *UsersService) recordUserChange(ctx context.Context, q database.Querier, id uint64, deleted bool) error {
+	_, err := q.ExecContext(
+		ctx,
+		`INSERT INTO user_changes (user_id, deleted) VALUES ($1, $2)`,
+		id,
+		deleted,
+	)
+	if err != nil {
+		return fmt.Errorf("[in services.UsersService.recordUserChange] failed to record change: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateUser attempts to perform an update of the user with the provided id,
-// updating, it to reflect the properties on the provided patch object. A
-// models.User or an error.
-func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.User) (models.User, error) {
-	return models.User{}, nil
+// updating it to reflect the properties on the provided patch object. The
+// password is only changed when a new one is provided. The updated models.User
+// or an error is returned, ErrNotFound if no user has the id and
+// ErrEmailTaken if another user has the email address.
+func (s *UsersService) UpdateUser(ctx context.Context, id uint64, patch models.User, password string) (models.User, error) {
+	s.logger.DebugContext(ctx, "Updating user", "id", id)
+
+	var hash string
+	if password != "" {
+		var err error
+		if hash, err = s.passwords.Hash(password); err != nil {
+			return models.User{}, fmt.Errorf("[in services.UsersService.UpdateUser] %w", err)
+		}
+	}
+
+	email, err := s.keyring.Encrypt(patch.Email)
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.UpdateUser] %w", err)
+	}
+
+	var user models.User
+	err = s.db.InTx(ctx, func(tx *database.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`
+			UPDATE users
+			SET name = $1,
+			    email = $2,
+			    email_index = $3,
+			    password_hash = COALESCE($4, password_hash),
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE id = $5
+			`,
+			patch.Name,
+			email,
+			s.emailIndex(patch.Email),
+			nullString(hash),
+			id,
+		)
+		if err != nil {
+			if database.IsUniqueViolation(err) {
+				return ErrEmailTaken
+			}
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		// MySQL reports no affected rows when nothing changed, so whether the
+		// user exists is checked by reading it back.
+		if user, err = s.readUser(ctx, tx, id); err != nil {
+			return err
+		}
+
+		return s.recordUserChange(ctx, tx, id, false)
+	})
+	if err != nil {
+		return models.User{}, fmt.Errorf("[in services.UsersService.UpdateUser] %w", err)
+	}
+
+	return user, nil
 }
 
-// CreateUser attempts to create the provided user, returning a fully hydrated
-// models.User or an error.
+// DeleteUser attempts to delete the user with the provided id, returning an
+// error, ErrNotFound if no user has the id.
 func (s *UsersService) DeleteUser(ctx context.Context, id uint64) error {
+	err := s.db.InTx(ctx, func(tx *database.Tx) error {
+		return s.deleteUser(ctx, tx, id)
+	})
+	if err != nil {
+		return fmt.Errorf("[in services.UsersService.DeleteUser] %w", err)
+	}
+
 	return nil
 }
 
-// CreateUser attempts to create the provided user, returning a fully hydrated
-// models.User or an error.
-func (s *UsersService) ListUsers(ctx context.Context, id uint64) ([]models.User, error) {
-	return []models.User{}, nil
+// deleteUser deletes a user as described by DeleteUser, running its queries
+// with q, which must be a transaction as the deletion is recorded separately.
+func (s *UsersService) deleteUser(ctx context.Context, q database.Querier, id uint64) error {
+	s.logger.DebugContext(ctx, "Deleting user", "id", id)
+
+	result, err := q.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("[in services.UsersService.deleteUser] failed to delete user: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("[in services.UsersService.deleteUser] failed to read deleted rows: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("[in services.UsersService.deleteUser] user %d: %w", id, ErrNotFound)
+	}
+
+	if err = s.recordUserChange(ctx, q, id, true); err != nil {
+		return fmt.Errorf("[in services.UsersService.deleteUser] %w", err)
+	}
+
+	return nil
+}
+
+// userSortColumns maps the sort fields accepted by ListUsers to columns.
+var userSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// ListUsersOptions holds the paging, sorting and filtering of ListUsers.
+type ListUsersOptions struct {
+	// Limit and Offset select the page of users returned.
+	Limit  int
+	Offset int
+	// Sort is the field users are ordered by, "id", "name", "created_at" or
+	// "updated_at". Defaults to "id". Emails are encrypted, so users cannot be
+	// ordered by them.
+	Sort string
+	// Desc orders users in descending order.
+	Desc bool
+	// Name only keeps users whose name contains the value, and Email those
+	// with the email address, both ignoring case. Emails are encrypted, so
+	// they can only be matched in full. Empty values are ignored.
+	Name  string
+	Email string
+}
+
+// ValidUserSort reports whether sort is a field ListUsers can order by.
+func ValidUserSort(sort string) bool {
+	_, ok := userSortColumns[sort]
+	return ok
+}
+
+// ListUsers attempts to list a page of users matching the provided options. The
+// users are returned along with the total number of users matching the
+// filters, or an error.
+func (s *UsersService) ListUsers(ctx context.Context, opts ListUsersOptions) ([]models.User, int, error) {
+	s.logger.DebugContext(ctx, "Listing users", "limit", opts.Limit, "offset", opts.Offset, "sort", opts.Sort)
+
+	column := "id"
+	if opts.Sort != "" {
+		var ok bool
+		if column, ok = userSortColumns[opts.Sort]; !ok {
+			return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] unsupported sort %q", opts.Sort)
+		}
+	}
+
+	direction := "ASC"
+	if opts.Desc {
+		direction = "DESC"
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	if opts.Name != "" {
+		args = append(args, "%"+escapeLike(strings.ToLower(opts.Name))+"%")
+		conditions = append(conditions, fmt.Sprintf("LOWER(name) LIKE $%d ESCAPE '!'", len(args)))
+	}
+	if opts.Email != "" {
+		args = append(args, s.emailIndex(opts.Email), strings.ToLower(opts.Email))
+		conditions = append(conditions, fmt.Sprintf(
+			"(email_index = $%d OR (email_index IS NULL AND LOWER(email) = $%d))",
+			len(args)-1,
+			len(args),
+		))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] failed to count users: %w", err)
+	}
+
+	// id breaks ties so pages are stable when sorting by a non-unique column.
+	rows, err := s.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`
+			SELECT id,
+			       name,
+			       email,
+			       password_hash,
+			       created_at,
+			       updated_at
+			FROM users
+			%s
+			ORDER BY %s %s, id %s
+			LIMIT $%d OFFSET $%d
+			`,
+			where, column, direction, direction, len(args)+1, len(args)+2,
+		),
+		append(args, opts.Limit, opts.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, opts.Limit)
+	for rows.Next() {
+		var user models.User
+
+		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] failed to scan user: %w", err)
+		}
+		if user.Email, err = s.keyring.Decrypt(user.Email); err != nil {
+			return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("[in services.UsersService.ListUsers] failed to read users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// Ways SearchUsers and SearchPosts can match, from best to most basic.
+const (
+	// SearchModeVector ranks posts by the cosine distance of their embedding
+	// to the query's. Requires the vector extension.
+	SearchModeVector = "vector"
+	// SearchModeTrigram ranks users by the trigram similarity of their name,
+	// tolerating typos. Requires the pg_trgm extension.
+	SearchModeTrigram = "trigram"
+	// SearchModeFullText matches the words of the query, by prefix for user
+	// names. Requires Postgres, or MySQL for posts.
+	SearchModeFullText = "full_text"
+	// SearchModeSubstring matches text containing the query, ignoring case.
+	SearchModeSubstring = "substring"
+)
+
+// SearchUsers attempts to find up to limit users whose name matches the
+// provided query, best matches first. Trigram similarity is used when trigram
+// is set, which requires the pg_trgm extension, and otherwise the best
+// matching the database supports. The users are returned along with the
+// search mode used, or an error.
+func (s *UsersService) SearchUsers(ctx context.Context, query string, limit int, trigram bool) ([]models.User, string, error) {
+	s.logger.DebugContext(ctx, "Searching users", "query", query, "limit", limit, "trigram", trigram)
+
+	var (
+		mode  string
+		where string
+		order string
+		args  []any
+	)
+	switch {
+	case trigram:
+		mode = SearchModeTrigram
+		where = `name % $1`
+		order = `similarity(name, $1) DESC`
+		args = []any{query}
+	case s.db.Dialect == database.DialectPostgres:
+		tsquery := prefixQuery(query)
+		if tsquery == "" {
+			return []models.User{}, SearchModeFullText, nil
+		}
+
+		mode = SearchModeFullText
+		where = `to_tsvector('simple', name) @@ to_tsquery('simple', $1)`
+		order = `ts_rank(to_tsvector('simple', name), to_tsquery('simple', $1)) DESC`
+		args = []any{tsquery}
+	default:
+		mode = SearchModeSubstring
+		where = `LOWER(name) LIKE $1 ESCAPE '!'`
+		order = `name`
+		args = []any{"%" + escapeLike(strings.ToLower(query)) + "%"}
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`
+			SELECT id,
+			       name,
+			       email,
+			       password_hash,
+			       created_at,
+			       updated_at
+			FROM users
+			WHERE %s
+			ORDER BY %s, id
+			LIMIT $2
+			`,
+			where, order,
+		),
+		append(args, limit)...,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("[in services.UsersService.SearchUsers] failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, limit)
+	for rows.Next() {
+		var user models.User
+
+		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, "", fmt.Errorf("[in services.UsersService.SearchUsers] failed to scan user: %w", err)
+		}
+		if user.Email, err = s.keyring.Decrypt(user.Email); err != nil {
+			return nil, "", fmt.Errorf("[in services.UsersService.SearchUsers] %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("[in services.UsersService.SearchUsers] failed to read users: %w", err)
+	}
+
+	return users, mode, nil
+}
+
+// prefixQuery converts a search query into a Postgres tsquery matching every
+// word of it as a prefix, e.g. "jo sm" into "jo:* & sm:*". Only letters and
+// digits are kept, so the result is always a valid tsquery.
+func prefixQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// ReencryptEmails encrypts the emails of users stored in plaintext or with a
+// key other than the current one, and fills in missing email indexes, working
+// through users batchSize at a time. The number of users updated or an error
+// is returned. Users are not marked as updated, as nothing visible changes.
+func (s *UsersService) ReencryptEmails(ctx context.Context, batchSize int) (int, error) {
+	s.logger.DebugContext(ctx, "Re-encrypting emails", "batch_size", batchSize)
+
+	type stored struct {
+		id      uint
+		email   string
+		indexed bool
+	}
+
+	var (
+		updated int
+		afterID uint
+	)
+	for {
+		rows, err := s.db.QueryContext(
+			ctx,
+			`
+			SELECT id, email, email_index IS NOT NULL
+			FROM users
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2
+			`,
+			afterID,
+			batchSize,
+		)
+		if err != nil {
+			return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] failed to query users: %w", err)
+		}
+
+		batch := make([]stored, 0, batchSize)
+		for rows.Next() {
+			var row stored
+			if err = rows.Scan(&row.id, &row.email, &row.indexed); err != nil {
+				rows.Close()
+				return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] failed to scan user: %w", err)
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] failed to read users: %w", err)
+		}
+
+		for _, row := range batch {
+			if row.indexed && s.keyring.Current(row.email) {
+				continue
+			}
+
+			plain, err := s.keyring.Decrypt(row.email)
+			if err != nil {
+				return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] user %d: %w", row.id, err)
+			}
+			email, err := s.keyring.Encrypt(plain)
+			if err != nil {
+				return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] user %d: %w", row.id, err)
+			}
+
+			// Matching on the old value skips users whose email changed since
+			// the batch was read, as they are already encrypted.
+			_, err = s.db.ExecContext(
+				ctx,
+				`UPDATE users SET email = $1, email_index = $2 WHERE id = $3 AND email = $4`,
+				email,
+				s.emailIndex(plain),
+				row.id,
+				row.email,
+			)
+			if err != nil {
+				return updated, fmt.Errorf("[in services.UsersService.ReencryptEmails] failed to update user %d: %w", row.id, err)
+			}
+			updated++
+		}
+
+		if len(batch) < batchSize {
+			return updated, nil
+		}
+		afterID = batch[len(batch)-1].id
+	}
+}
+
+// emailIndex returns the blind index users are found by email with.
+func (s *UsersService) emailIndex(email string) string {
+	return s.keyring.Index(strings.ToLower(email))
+}
+
+// escapeLike escapes the LIKE wildcards in s, using ! as the escape character
+// since backslashes are treated differently by MySQL.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
 }
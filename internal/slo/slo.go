@@ -0,0 +1,193 @@
+package slo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jha-captech/blog/internal/clock"
+)
+
+// retention is how far back request outcomes are kept, in one minute buckets.
+const retention = 24 * time.Hour
+
+// Objectives holds the targeted fraction of good requests, between 0 and 1.
+type Objectives struct {
+	// Availability is the targeted fraction of requests that do not fail with
+	// a server error.
+	Availability float64 `json:"availability"`
+	// Latency is the targeted fraction of requests served within their route's
+	// latency budget.
+	Latency float64 `json:"latency"`
+}
+
+// counts holds request outcomes for a single route or for all routes.
+type counts struct {
+	Requests int64
+	Errors   int64
+	Slow     int64
+}
+
+func (c *counts) add(o counts) {
+	c.Requests += o.Requests
+	c.Errors += o.Errors
+	c.Slow += o.Slow
+}
+
+// bucket holds the outcomes of requests completed in a single minute.
+type bucket struct {
+	minute int64
+	total  counts
+	routes map[string]*counts
+}
+
+// Tracker records request outcomes in memory and reports SLO attainment over
+// rolling windows of up to 24 hours. Outcomes are lost when the process
+// restarts and are not shared between instances.
+type Tracker struct {
+	clock      clock.Clock
+	objectives Objectives
+
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+// NewTracker creates a new Tracker and returns a pointer to it.
+func NewTracker(clk clock.Clock, objectives Objectives) *Tracker {
+	return &Tracker{
+		clock:      clk,
+		objectives: objectives,
+		buckets:    make([]bucket, int(retention/time.Minute)),
+	}
+}
+
+// Record records the outcome of a single request to route.
+func (t *Tracker) Record(route string, serverError bool, slow bool) {
+	o := counts{Requests: 1}
+	if serverError {
+		o.Errors = 1
+	}
+	if slow {
+		o.Slow = 1
+	}
+
+	minute := t.clock.Now().Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &t.buckets[minute%int64(len(t.buckets))]
+	if b.minute != minute {
+		*b = bucket{minute: minute, routes: make(map[string]*counts)}
+	}
+
+	b.total.add(o)
+
+	rc, ok := b.routes[route]
+	if !ok {
+		rc = &counts{}
+		b.routes[route] = rc
+	}
+	rc.add(o)
+}
+
+// Attainment describes how well requests met the objectives in a window.
+type Attainment struct {
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+	Slow     int64 `json:"slow"`
+
+	// Availability and Latency are the fractions of good requests.
+	Availability float64 `json:"availability"`
+	Latency      float64 `json:"latency"`
+
+	// AvailabilityBudgetRemaining and LatencyBudgetRemaining are the fractions
+	// of the error budgets that have not been spent. They are negative once the
+	// budget is exhausted.
+	AvailabilityBudgetRemaining float64 `json:"availability_budget_remaining"`
+	LatencyBudgetRemaining      float64 `json:"latency_budget_remaining"`
+}
+
+// Report describes SLO attainment over a single window.
+type Report struct {
+	Window     string                `json:"window"`
+	Objectives Objectives            `json:"objectives"`
+	Overall    Attainment            `json:"overall"`
+	Routes     map[string]Attainment `json:"routes"`
+}
+
+// Report returns SLO attainment over the provided window, which is capped at
+// 24 hours.
+func (t *Tracker) Report(window time.Duration) Report {
+	window = min(window, retention)
+	since := t.clock.Now().Add(-window).Unix() / 60
+
+	var total counts
+	routes := make(map[string]*counts)
+
+	t.mu.Lock()
+	for _, b := range t.buckets {
+		if b.routes == nil || b.minute <= since {
+			continue
+		}
+
+		total.add(b.total)
+		for route, c := range b.routes {
+			rc, ok := routes[route]
+			if !ok {
+				rc = &counts{}
+				routes[route] = rc
+			}
+			rc.add(*c)
+		}
+	}
+	t.mu.Unlock()
+
+	report := Report{
+		Window:     window.String(),
+		Objectives: t.objectives,
+		Overall:    t.attainment(total),
+		Routes:     make(map[string]Attainment, len(routes)),
+	}
+	for route, c := range routes {
+		report.Routes[route] = t.attainment(*c)
+	}
+
+	return report
+}
+
+// attainment computes the attainment of the provided counts.
+func (t *Tracker) attainment(c counts) Attainment {
+	a := Attainment{
+		Requests:                    c.Requests,
+		Errors:                      c.Errors,
+		Slow:                        c.Slow,
+		Availability:                1,
+		Latency:                     1,
+		AvailabilityBudgetRemaining: 1,
+		LatencyBudgetRemaining:      1,
+	}
+
+	if c.Requests == 0 {
+		return a
+	}
+
+	a.Availability = 1 - float64(c.Errors)/float64(c.Requests)
+	a.Latency = 1 - float64(c.Slow)/float64(c.Requests)
+	a.AvailabilityBudgetRemaining = budgetRemaining(a.Availability, t.objectives.Availability)
+	a.LatencyBudgetRemaining = budgetRemaining(a.Latency, t.objectives.Latency)
+
+	return a
+}
+
+// budgetRemaining returns the fraction of the error budget implied by target
+// that has not been spent given the attained fraction of good requests.
+func budgetRemaining(attained float64, target float64) float64 {
+	budget := 1 - target
+	if budget <= 0 {
+		if attained >= 1 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - (1-attained)/budget
+}
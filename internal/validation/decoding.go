@@ -0,0 +1,20 @@
+package validation
+
+import "context"
+
+// strictKey is the key strict decoding is enabled under in a context.
+type strictKey struct{}
+
+// WithStrictDecoding returns a copy of ctx in which request bodies are decoded
+// strictly when strict is set, rejecting fields the request type does not
+// have. Otherwise unknown fields are ignored, so older clients still sending
+// removed fields keep working.
+func WithStrictDecoding(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictKey{}, strict)
+}
+
+// StrictDecoding reports whether request bodies are decoded strictly in ctx.
+func StrictDecoding(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictKey{}).(bool)
+	return strict
+}
@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Params reads typed path and query parameters from a request, recording a
+// problem in Problems for every parameter that is malformed or out of range.
+// The zero value of the type is returned for invalid parameters, so callers
+// should check Problems before using any of them.
+type Params struct {
+	Problems Problems
+
+	request *http.Request
+	query   url.Values
+}
+
+// NewParams creates a new Params reading from the provided request and returns
+// a pointer to it.
+func NewParams(r *http.Request) *Params {
+	return &Params{
+		Problems: make(Problems),
+		request:  r,
+		query:    r.URL.Query(),
+	}
+}
+
+// PathID returns the path parameter name, which must be a positive integer.
+func (p *Params) PathID(name string) uint64 {
+	id, err := strconv.ParseUint(p.request.PathValue(name), 10, 64)
+	if err != nil || id == 0 {
+		p.Problems.Add(name, "must be a positive integer")
+		return 0
+	}
+	return id
+}
+
+// QueryID returns the query parameter name, which must be a positive integer
+// when set, or 0 when it is not set.
+func (p *Params) QueryID(name string) uint64 {
+	value := p.query.Get(name)
+	if value == "" {
+		return 0
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		p.Problems.Add(name, "must be a positive integer")
+		return 0
+	}
+	return id
+}
+
+// QueryInt returns the query parameter name, which must be an integer between
+// min and max when set, or def when it is not set. Pass math.MaxInt as max for
+// no upper bound.
+func (p *Params) QueryInt(name string, def int, min int, max int) int {
+	value := p.query.Get(name)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		if max == math.MaxInt {
+			p.Problems.Add(name, fmt.Sprintf("must be an integer of at least %d", min))
+		} else {
+			p.Problems.Add(name, fmt.Sprintf("must be an integer between %d and %d", min, max))
+		}
+		return 0
+	}
+	return n
+}
+
+// Query returns the query parameter name, checked against rules when set.
+func (p *Params) Query(name string, rules ...Rule) string {
+	value := p.query.Get(name)
+	if value != "" {
+		p.Problems.Field(name, value, rules...)
+	}
+	return value
+}
+
+// QueryTime returns the query parameter name, which must be an RFC 3339 time
+// when set, or def when it is not set.
+func (p *Params) QueryTime(name string, def time.Time) time.Time {
+	value := p.query.Get(name)
+	if value == "" {
+		return def
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.Problems.Add(name, "must be an RFC 3339 time, e.g. 2024-05-15T12:00:00Z")
+		return time.Time{}
+	}
+	return t
+}
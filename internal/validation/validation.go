@@ -0,0 +1,152 @@
+// Package validation checks request bodies and parameters against declarative
+// rules, collecting a problem for each invalid field so that clients can be
+// told about every mistake at once.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Problems maps invalid fields to a description of what is wrong with them. An
+// empty Problems means everything checked was valid.
+type Problems map[string]string
+
+// Add records message as the problem with field, unless a problem has already
+// been recorded for it.
+func (p Problems) Add(field string, message string) {
+	if _, ok := p[field]; !ok {
+		p[field] = message
+	}
+}
+
+// Check records message as the problem with field when ok is false. It is used
+// for conditions the string rules do not cover.
+func (p Problems) Check(ok bool, field string, message string) {
+	if !ok {
+		p.Add(field, message)
+	}
+}
+
+// Field checks value against rules in order, recording the first problem
+// found against field.
+func (p Problems) Field(field string, value string, rules ...Rule) {
+	for _, rule := range rules {
+		if problem := rule(value); problem != "" {
+			p.Add(field, problem)
+			return
+		}
+	}
+}
+
+// Rule checks a string value, returning the problem with it or an empty string
+// if it is valid.
+type Rule func(value string) (problem string)
+
+// Required rejects values that are empty or only whitespace.
+func Required() Rule {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "must be set"
+		}
+		return ""
+	}
+}
+
+// Length rejects values shorter than min or longer than max characters.
+func Length(min int, max int) Rule {
+	return func(value string) string {
+		if n := utf8.RuneCountInString(value); n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d characters", min, max)
+		}
+		return ""
+	}
+}
+
+// MinLength rejects values shorter than min characters.
+func MinLength(min int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) < min {
+			return fmt.Sprintf("must be at least %d characters", min)
+		}
+		return ""
+	}
+}
+
+// MaxLength rejects values longer than max characters.
+func MaxLength(max int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > max {
+			return fmt.Sprintf("must be at most %d characters", max)
+		}
+		return ""
+	}
+}
+
+// MaxBytes rejects values longer than max bytes, for limits of storage or
+// algorithms rather than of what users see.
+func MaxBytes(max int) Rule {
+	return func(value string) string {
+		if len(value) > max {
+			return fmt.Sprintf("must be at most %d bytes", max)
+		}
+		return ""
+	}
+}
+
+// Email rejects values that are not a bare email address, such as
+// "jane@example.com". Display names like "Jane <jane@example.com>" are
+// rejected too.
+func Email() Rule {
+	return func(value string) string {
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return "must be a valid email address"
+		}
+		return ""
+	}
+}
+
+// OneOf rejects values other than those allowed.
+func OneOf(allowed ...string) Rule {
+	return func(value string) string {
+		if !slices.Contains(allowed, value) {
+			return "must be one of " + list(allowed)
+		}
+		return ""
+	}
+}
+
+// Matches rejects values not matching pattern, reporting message.
+func Matches(pattern *regexp.Regexp, message string) Rule {
+	return func(value string) string {
+		if !pattern.MatchString(value) {
+			return message
+		}
+		return ""
+	}
+}
+
+// AbsoluteURL rejects values that are not absolute URLs with a host and one of
+// the provided schemes.
+func AbsoluteURL(schemes ...string) Rule {
+	return func(value string) string {
+		u, err := url.Parse(value)
+		if err != nil || !slices.Contains(schemes, u.Scheme) || u.Host == "" {
+			return "must be an absolute " + list(schemes) + " URL"
+		}
+		return ""
+	}
+}
+
+// list joins values into an English list, e.g. "a, b or c".
+func list(values []string) string {
+	if len(values) < 2 {
+		return strings.Join(values, "")
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}